@@ -6,15 +6,27 @@
 package amp
 
 import (
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/joseph-beck/amp/pkg/binding"
+	amperr "github.com/joseph-beck/amp/pkg/error"
 	"github.com/joseph-beck/amp/pkg/status"
 	"github.com/pelletier/go-toml/v2"
 	"gopkg.in/yaml.v3"
@@ -27,23 +39,40 @@ var (
 	xmlContentType   = []string{"application/xml; charset=utf-8"}
 	htmlContentType  = []string{"text/html; charset=utf-8"}
 	plainContentType = []string{"text/plain; charset=utf-8"}
+
+	problemJSONContentType = []string{"application/problem+json; charset=utf-8"}
+	problemXMLContentType  = []string{"application/problem+xml; charset=utf-8"}
+
+	// Media types that problem details can be rendered as, in order of preference.
+	problemMediaTypes = []string{"application/problem+json", "application/json", "application/problem+xml", "application/xml"}
 )
 
+// Ctx implements context.Context, so it can be given to anything that needs a context.
+var _ context.Context = (*Ctx)(nil)
+
 // Ctx for storing information about the request, and for responding back.
 // All members are unexported, but will often have access via the Ctx methods.
+// A Ctx is reused by the Mux once the request has finished,
+// so it should not be used after its Handler has returned.
 type Ctx struct {
 	// Stores the writer of the context.
 	// There are many shortcuts for accessing specific parts of the writer.
 	// To get the http.ResponseWriter use Ctx.Writer().
+	// This is the response below by default.
 	writer http.ResponseWriter
 
+	// Wraps the http.ResponseWriter given to the context.
+	// Defers writing the status until the body is written or the request has finished,
+	// tracking the status, size and whether the response has been written.
+	response responseWriter
+
 	// Stores the request of the context.
 	// There are many shortcuts for accessing specific parts of the request.
 	// To get the *http.Request use Ctx.Request().
 	request *http.Request
 
 	// Stores the current status of the context.
-	// This is also written to the writer above.
+	// This is also given to the writer above, which writes it with the body.
 	status int
 
 	// Has the current context been aborted?
@@ -67,12 +96,56 @@ type Ctx struct {
 	// Stores the index or the current handler that we are on.
 	// When creating a new context, this starts of at -1.
 	index int
+
+	// The Mux that is serving the Ctx.
+	// This is nil when the Ctx is not created by a Mux.
+	mux *Mux
+
+	// Identifier of the request, often set by the requestid middleware.
+	// Included in the logs of the Mux when it is not empty.
+	requestID string
+
+	// Body of the request before it was limited by LimitBody.
+	body io.ReadCloser
+
+	// Body given to the request by LimitBody, used to check it has not since been replaced.
+	limitedBody io.ReadCloser
+
+	// Set when the request context has been replaced by a context derived from the Ctx, see WithContext.
+	// This is nil by default, the Ctx then uses the request context.
+	// Atomic, as the Ctx can be used as a context by other goroutines, such as those of a context.WithTimeout.
+	derived atomic.Pointer[derivedContext]
+
+	// Number of lookups of Value in progress through derived contexts.
+	// Each derived context reaches the Ctx again, which then looks in the context that it replaced.
+	lookups atomic.Int32
+}
+
+// A context derived from a Ctx, given to Ctx.WithContext.
+// The Ctx uses this in place of the request context, which would otherwise use the Ctx itself.
+type derivedContext struct {
+	// The derived context, used for the values it adds.
+	context context.Context
+
+	// The derived context that this replaced, nil if the replaced context was not derived from the Ctx.
+	prev *derivedContext
+
+	// Context of the Ctx before any derived context was given.
+	parent context.Context
+
+	// Done channel of the derived context, taken when it was given.
+	done <-chan struct{}
+
+	// Deadline of the derived context, taken when it was given.
+	deadline time.Time
+
+	// Does the derived context have a deadline?
+	hasDeadline bool
 }
 
 // Create a new context with a writer and a request.
 func newCtx(w http.ResponseWriter, r *http.Request) *Ctx {
-	return &Ctx{
-		writer:   w,
+	ctx := &Ctx{
 		request:  r,
 		status:   status.OK,
 		aborted:  false,
@@ -81,6 +154,29 @@ func newCtx(w http.ResponseWriter, r *http.Request) *Ctx {
 		handlers: []Handler{},
 		index:    -1,
 	}
+
+	ctx.response.reset(w)
+	ctx.writer = &ctx.response
+
+	return ctx
+}
+
+// Reset the context, so that it can be reused for a new writer and request.
+// Keeps the values map, which is cleared, to prevent allocating a new one.
+func (ctx *Ctx) reset(w http.ResponseWriter, r *http.Request) {
+	ctx.request = r
+	ctx.status = status.OK
+	ctx.aborted = false
+	clear(ctx.values)
+	ctx.handlers = nil
+	ctx.index = -1
+	ctx.requestID = ""
+	ctx.body = nil
+	ctx.limitedBody = nil
+	ctx.derived.Store(nil)
+
+	ctx.response.reset(w)
+	ctx.writer = &ctx.response
 }
 
 // Write the content type of the writer of the Ctx.
@@ -98,10 +194,23 @@ func (ctx *Ctx) Writer() http.ResponseWriter {
 }
 
 // Set the writer of the Ctx.
+// Writes to the given writer are not tracked by Written and Size,
+// unless it writes through the previous writer of the Ctx.
 func (ctx *Ctx) SetWriter(writer http.ResponseWriter) {
 	ctx.writer = writer
 }
 
+// Checks if the status and headers of the Ctx have been written.
+// Once written, headers and the status can no longer be changed.
+func (ctx *Ctx) Written() bool {
+	return ctx.response.written
+}
+
+// Get the number of bytes of the body that have been written by the Ctx.
+func (ctx *Ctx) Size() int {
+	return ctx.response.size
+}
+
 // Get request of the Ctx.
 func (ctx *Ctx) Request() *http.Request {
 	return ctx.request
@@ -112,6 +221,30 @@ func (ctx *Ctx) SetRequest(request *http.Request) {
 	ctx.request = request
 }
 
+// Limit the size of the body of the request to a given number of bytes, replacing any limit already set,
+// such as the MaxBodySize of the Mux. A zero or negative value removes the limit.
+// Reading more than the limit gives a *http.MaxBytesError, which is handled as an error.PayloadTooLarge, or a 413.
+// This should be used before the body is read, often with the BodyLimit middleware.
+func (ctx *Ctx) LimitBody(n int64) {
+	if ctx.request == nil || ctx.request.Body == nil {
+		return
+	}
+
+	// the body is limited again, rather than the limited body, so that a limit can be raised.
+	if ctx.limitedBody == nil || ctx.request.Body != ctx.limitedBody {
+		ctx.body = ctx.request.Body
+	}
+
+	if n <= 0 {
+		ctx.request.Body = ctx.body
+		ctx.limitedBody = nil
+		return
+	}
+
+	ctx.limitedBody = http.MaxBytesReader(ctx.response.ResponseWriter, ctx.body, n)
+	ctx.request.Body = ctx.limitedBody
+}
+
 // Set a value in the Ctx values map.
 func (ctx *Ctx) Set(key string, val any) {
 	ctx.valuesMu.Lock()
@@ -133,6 +266,218 @@ func (ctx *Ctx) Get(key string) (any, error) {
 	return val, nil
 }
 
+// Get the identifier of the request of the Ctx, empty if it has not been set.
+func (ctx *Ctx) RequestID() string {
+	return ctx.requestID
+}
+
+// Set the identifier of the request of the Ctx.
+// This is included as the "request_id" attribute in the logs of the Mux.
+func (ctx *Ctx) SetRequestID(id string) {
+	ctx.requestID = id
+}
+
+// Get the attributes describing the Ctx in logs.
+func (ctx *Ctx) logAttrs() []any {
+	if ctx.requestID == "" {
+		return nil
+	}
+
+	return []any{"request_id", ctx.requestID}
+}
+
+// Get the logger of the Mux, with the request ID of the Ctx if it has one.
+// If the Ctx is not from a Mux, slog.Default() is used.
+func (ctx *Ctx) Logger() *slog.Logger {
+	logger := slog.Default()
+	if ctx.mux != nil {
+		logger = ctx.mux.Logger()
+	}
+
+	if attrs := ctx.logAttrs(); attrs != nil {
+		return logger.With(attrs...)
+	}
+
+	return logger
+}
+
+// Respond with an error, using the ErrorHandler of the Mux, and log the error.
+// Errors with a status of 500 or more are logged as errors, those of the client as warnings.
+// Once handled the error should not be returned as well, else it is responded with twice.
+// The Mux handles any errors returned by Handlers itself,
+// this is used by middleware that need the response to an error, for example to log it.
+func (ctx *Ctx) HandleError(err error) {
+	// errors of the client are not errors of the server, so they are only warned about.
+	code := amperr.From(err).Status
+	if code >= status.InternalServerError {
+		ctx.Logger().Error(err.Error(), "method", ctx.Method(), "path", ctx.Path(), "status", code)
+	} else {
+		ctx.Logger().Warn(err.Error(), "method", ctx.Method(), "path", ctx.Path(), "status", code)
+	}
+
+	if ctx.mux != nil {
+		ctx.mux.errorHandler(ctx, err)
+		return
+	}
+
+	DefaultErrorHandler(ctx, err)
+}
+
+// Register a function to run when the Mux of the Ctx is shutdown, see Mux.OnShutdown.
+// Allows middleware to stop the goroutines it starts, does nothing if the Ctx has no Mux.
+func (ctx *Ctx) OnShutdown(fn func()) {
+	if ctx.mux != nil {
+		ctx.mux.OnShutdown(fn)
+	}
+}
+
+// Get the context of the request of the Ctx.
+// If the Ctx has no request, context.Background() is used.
+// If the request context was derived from the Ctx, the context it replaced is used.
+func (ctx *Ctx) context() context.Context {
+	if derived := ctx.derived.Load(); derived != nil {
+		return derived.parent
+	}
+
+	if ctx.request == nil {
+		return context.Background()
+	}
+
+	return ctx.request.Context()
+}
+
+// Key used to check if a context has been derived from a Ctx.
+type ctxProbeKey struct{}
+
+// Replace the context of the request of the Ctx.
+// Often used by middleware, for example to add a deadline or a value for later handlers.
+//
+//	c, cancel := context.WithTimeout(ctx.Request().Context(), 5*time.Second)
+//	defer cancel()
+//
+//	ctx.WithContext(c)
+//	return ctx.Next()
+//
+// The given context can be derived from the Ctx itself, or from Ctx.Request().Context().
+func (ctx *Ctx) WithContext(c context.Context) {
+	if c.Value(ctxProbeKey{}) != ctx {
+		ctx.derived.Store(nil)
+		ctx.request = ctx.request.WithContext(c)
+		return
+	}
+
+	// the Ctx still uses the context it is replacing here, so the derived context can be used.
+	derived := &derivedContext{
+		context: c,
+		prev:    ctx.derived.Load(),
+		parent:  ctx.context(),
+		done:    c.Done(),
+	}
+	derived.deadline, derived.hasDeadline = c.Deadline()
+
+	ctx.derived.Store(derived)
+	ctx.request = ctx.request.WithContext(c)
+}
+
+// Get the deadline of the request context of the Ctx.
+// Implements context.Context.
+func (ctx *Ctx) Deadline() (time.Time, bool) {
+	if derived := ctx.derived.Load(); derived != nil {
+		return derived.deadline, derived.hasDeadline
+	}
+
+	return ctx.context().Deadline()
+}
+
+// Get a channel that is closed when the request is cancelled,
+// for example when the client disconnects or the deadline is reached.
+// Implements context.Context.
+func (ctx *Ctx) Done() <-chan struct{} {
+	if derived := ctx.derived.Load(); derived != nil {
+		return derived.done
+	}
+
+	return ctx.context().Done()
+}
+
+// Get the reason that the request context was cancelled, nil if it has not been.
+// Implements context.Context.
+func (ctx *Ctx) Err() error {
+	derived := ctx.derived.Load()
+	if derived == nil {
+		return ctx.context().Err()
+	}
+
+	select {
+	case <-derived.done:
+	default:
+		return nil
+	}
+
+	if err := derived.parent.Err(); err != nil {
+		return err
+	}
+
+	if derived.hasDeadline && !time.Now().Before(derived.deadline) {
+		return context.DeadlineExceeded
+	}
+
+	return context.Canceled
+}
+
+// Get a value from the request context of the Ctx.
+// If the request context does not have the value, and the key is a string,
+// the value is taken from the Ctx values map instead.
+// Implements context.Context.
+func (ctx *Ctx) Value(key any) any {
+	if _, ok := key.(ctxProbeKey); ok {
+		return ctx
+	}
+
+	val := ctx.lookup(key)
+	if val != nil {
+		return val
+	}
+
+	str, ok := key.(string)
+	if !ok {
+		return nil
+	}
+
+	ctx.valuesMu.Lock()
+	defer ctx.valuesMu.Unlock()
+
+	return ctx.values[str]
+}
+
+// Get a value from the request context of the Ctx.
+// A derived request context gives its own values, then reaches the Ctx again,
+// which then looks in the context that the derived context replaced, rather than in the derived context again.
+func (ctx *Ctx) lookup(key any) any {
+	derived := ctx.derived.Load()
+	if derived == nil {
+		return ctx.context().Value(key)
+	}
+
+	depth := ctx.lookups.Add(1)
+	defer ctx.lookups.Add(-1)
+
+	parent := derived.parent
+	for range depth - 1 {
+		if derived == nil {
+			break
+		}
+
+		derived = derived.prev
+	}
+
+	if derived == nil {
+		return parent.Value(key)
+	}
+
+	return derived.context.Value(key)
+}
+
 // Get the path of the current Ctx.
 func (ctx *Ctx) Path() string {
 	return ctx.request.URL.Path
@@ -153,16 +498,116 @@ func (ctx *Ctx) Origin() string {
 	return ctx.request.Host
 }
 
+// Get the hop of the client of the Ctx, from the forwarding headers.
+// Returns false if the request is not from a trusted proxy of the Mux, or the headers give no client.
+func (ctx *Ctx) client() (hop, bool) {
+	if ctx.mux == nil || len(ctx.mux.trustedProxies) == 0 {
+		return hop{}, false
+	}
+
+	return resolveClient(ctx.mux.trustedProxies, parseAddr(ctx.request.RemoteAddr), ctx.request.Header)
+}
+
+// Get the IP address of the client of the current Ctx.
+// If the request is from a trusted proxy of the Mux,
+// the Forwarded, X-Forwarded-For and X-Real-IP headers are used.
+// Otherwise, the remote address of the request is used.
+func (ctx *Ctx) ClientIP() string {
+	if h, ok := ctx.client(); ok {
+		return h.addr.String()
+	}
+
+	if addr := parseAddr(ctx.request.RemoteAddr); addr.IsValid() {
+		return addr.String()
+	}
+
+	return ctx.request.RemoteAddr
+}
+
+// Get the scheme the client used for the current Ctx, "http" or "https".
+// If the request is from a trusted proxy of the Mux, the Forwarded and X-Forwarded-Proto headers are used.
+func (ctx *Ctx) Scheme() string {
+	if h, ok := ctx.client(); ok && h.proto != "" {
+		return h.proto
+	}
+
+	if ctx.request.TLS != nil {
+		return "https"
+	}
+
+	return "http"
+}
+
+// Get the host the client used for the current Ctx.
+// If the request is from a trusted proxy of the Mux, the Forwarded and X-Forwarded-Host headers are used.
+func (ctx *Ctx) Host() string {
+	if h, ok := ctx.client(); ok && h.host != "" {
+		return h.host
+	}
+
+	return ctx.request.Host
+}
+
 // Go to the next method in the Ctx.
+// Runs the remaining Handlers of the Ctx, returning once they have all ran,
+// one returns an error or one aborts the Ctx.
+// The next Handler is always ran, even if the Ctx has already been aborted.
+// As the rest of the chain runs within Next, middleware can wrap it,
+// for example to recover from panics or to time the request.
 func (ctx *Ctx) Next() error {
 	ctx.index++
-	if ctx.index <= len(ctx.handlers) {
-		return ctx.handlers[ctx.index](ctx)
+	for ctx.index < len(ctx.handlers) {
+		err := ctx.handlers[ctx.index](ctx)
+		if err != nil {
+			return err
+		}
+
+		if ctx.aborted {
+			return nil
+		}
+
+		ctx.index++
 	}
 
 	return nil
 }
 
+// Skip the remaining Handlers of the Ctx, without aborting it.
+// Used by middleware that runs the rest of the chain itself, for example on a Copy of the Ctx.
+func (ctx *Ctx) Skip() {
+	ctx.index = len(ctx.handlers)
+}
+
+// Copy the Ctx, so that it can be used outside of its Handler, for example in a goroutine.
+// The copy has its own values, status and writer, the writer discards anything written to it,
+// use SetWriter to give it another.
+// Calling Next on the copy runs the remaining Handlers of the Ctx,
+// the Ctx itself still runs them unless Skip or Abort is called.
+func (ctx *Ctx) Copy() *Ctx {
+	cp := &Ctx{
+		request:   ctx.request,
+		status:    ctx.status,
+		aborted:   ctx.aborted,
+		values:    make(map[string]any, len(ctx.values)),
+		handlers:  ctx.handlers,
+		index:     ctx.index,
+		mux:       ctx.mux,
+		requestID: ctx.requestID,
+	}
+
+	cp.derived.Store(ctx.derived.Load())
+
+	ctx.valuesMu.Lock()
+	maps.Copy(cp.values, ctx.values)
+	ctx.valuesMu.Unlock()
+
+	cp.response.reset(&discardWriter{header: ctx.writer.Header().Clone()})
+	cp.response.status = ctx.response.status
+	cp.writer = &cp.response
+
+	return cp
+}
+
 // Get a param from the Ctx, this will error if the param cannot be found.
 func (ctx *Ctx) Param(key string) (string, error) {
 	val := ctx.request.PathValue(key)
@@ -289,14 +734,103 @@ func (ctx *Ctx) QueryBool(key string, def ...bool) (bool, error) {
 	return val, nil
 }
 
+// Parses the multipart form of the request, using the MaxMultipartMemory and MaxFileSize of the Mux.
+// Returns an error.PayloadTooLarge if any file is larger than the MaxFileSize, once the form has been read.
+func (ctx *Ctx) parseMultipartForm() error {
+	maxMemory, maxFileSize := int64(binding.DefaultMaxMultipartMemory), int64(0)
+	if ctx.mux != nil {
+		maxMemory, maxFileSize = ctx.mux.maxMultipartMemory, ctx.mux.maxFileSize
+	}
+
+	// parses the url encoded form first, as ParseMultipartForm hides its errors when the body is not multipart.
+	if err := ctx.request.ParseForm(); err != nil {
+		return err
+	}
+
+	if err := ctx.request.ParseMultipartForm(maxMemory); err != nil {
+		return err
+	}
+
+	if maxFileSize <= 0 {
+		return nil
+	}
+
+	for _, fhs := range ctx.request.MultipartForm.File {
+		for _, fh := range fhs {
+			if fh.Size > maxFileSize {
+				return amperr.PayloadTooLarge(fmt.Sprintf("file %q is larger than %d bytes", fh.Filename, maxFileSize))
+			}
+		}
+	}
+
+	return nil
+}
+
+// Removes the temporary files of the multipart form of the request, once the handlers have finished.
+// net/http only removes those of the request it gave, not of a request replaced, such as by WithContext.
+func (ctx *Ctx) removeMultipartForm() {
+	if ctx.request != nil && ctx.request.MultipartForm != nil {
+		_ = ctx.request.MultipartForm.RemoveAll()
+	}
+}
+
+// Get the first file of a given key from the multipart form of the request.
+// Errors if the request is not a multipart form, or the form does not have the file.
+func (ctx *Ctx) FormFile(key string) (*multipart.FileHeader, error) {
+	if err := ctx.parseMultipartForm(); err != nil {
+		return nil, err
+	}
+
+	fhs := ctx.request.MultipartForm.File[key]
+	if len(fhs) == 0 {
+		return nil, errors.New("error, form file not found")
+	}
+
+	return fhs[0], nil
+}
+
+// Save a file of a multipart form to a given destination, creating any directories of the destination.
+//
+//	file, err := ctx.FormFile("avatar")
+//	...
+//	err = ctx.SaveUploadedFile(file, filepath.Join("uploads", filepath.Base(file.Filename)))
+func (ctx *Ctx) SaveUploadedFile(file *multipart.FileHeader, dst string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+		return err
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, src)
+	return err
+}
+
 // Set the status of the current Ctx.
+// The status is written along with the body, or once the request has finished,
+// so headers can still be set after this.
 func (ctx *Ctx) Status(status int) {
 	ctx.status = status
 	ctx.writer.WriteHeader(status)
 }
 
 // Get the status of the current Ctx.
+// Once the status has been written, this is the status that was written,
+// which can also be set by writing to the writer of the Ctx, such as by a wrapped http.HandlerFunc.
 func (ctx *Ctx) GetStatus() int {
+	if ctx.response.written || ctx.writer == &ctx.response {
+		return ctx.response.status
+	}
+
 	return ctx.status
 }
 
@@ -426,6 +960,70 @@ func (ctx *Ctx) RenderXML(status int, obj any) error {
 	return ctx.RenderBytes(status, body)
 }
 
+// Render an obj in the format most preferred by the Accept header of the request, with a given status code.
+// Chooses from the given offers, media types such as "application/json",
+// or from every Renderer of the Mux if none are given.
+// Sets the Vary header to Accept, as the response depends on it.
+//
+//	return ctx.Negotiate(status.OK, user, "application/json", "application/xml")
+//
+// Returns an error.NotAcceptable if the Accept header accepts none of the offers,
+// or an error if an offer has no Renderer.
+// Renderers can be added using Mux.Renderer.
+func (ctx *Ctx) Negotiate(status int, obj any, offers ...string) error {
+	renderers := defaultRenderers
+	if ctx.mux != nil {
+		renderers = ctx.mux.allRenderers()
+	}
+
+	if len(offers) == 0 {
+		offers = make([]string, 0, len(renderers))
+		for _, r := range renderers {
+			offers = append(offers, r.mediaType)
+		}
+	} else {
+		normalised := make([]string, 0, len(offers))
+		for _, offer := range offers {
+			mediaType, _, _ := strings.Cut(offer, ";")
+			normalised = append(normalised, strings.ToLower(strings.TrimSpace(mediaType)))
+		}
+
+		offers = normalised
+	}
+
+	addVary(ctx.writer.Header(), "Accept")
+
+	mediaType, ok := negotiate(strings.Join(ctx.request.Header.Values("Accept"), ","), offers)
+	if !ok {
+		return amperr.NotAcceptable("")
+	}
+
+	r, ok := findRenderer(renderers, mediaType)
+	if !ok {
+		return fmt.Errorf("error, no renderer for %s", mediaType)
+	}
+
+	writeContentType(ctx.writer, r.contentType)
+
+	body, err := r.render(obj)
+	if err != nil {
+		return err
+	}
+
+	return ctx.RenderBytes(status, body)
+}
+
+// Adds a value to the Vary header, if it does not already have it.
+func addVary(header http.Header, value string) {
+	for _, val := range headerList(header, "Vary") {
+		if val == "*" || strings.EqualFold(val, value) {
+			return
+		}
+	}
+
+	header.Add("Vary", value)
+}
+
 // Render a given HTML file with a given status code.
 func (ctx *Ctx) RenderHTML(status int, file string) error {
 	writeContentType(ctx.writer, htmlContentType)
@@ -435,9 +1033,128 @@ func (ctx *Ctx) RenderHTML(status int, file string) error {
 	return nil
 }
 
+// Render an error as problem details, RFC 9457, with the status of the error.
+// If the error is not an *error.Error, a status.InternalServerError is rendered,
+// the message of the error is not given to the client.
+// Any headers of the error are set on the response.
+// Renders application/problem+xml if the Accept header prefers XML, otherwise application/problem+json.
+// Nothing is rendered if the response has already been written.
+func (ctx *Ctx) RenderProblem(err error) error {
+	if ctx.Written() {
+		return nil
+	}
+
+	e := amperr.From(err)
+
+	header := ctx.writer.Header()
+	for key, val := range e.Headers {
+		header[key] = val
+	}
+
+	problem := e.Problem()
+	problem.Instance = ctx.Path()
+
+	mediaType, _ := negotiate(strings.Join(ctx.request.Header.Values("Accept"), ","), problemMediaTypes)
+	if strings.HasSuffix(mediaType, "xml") {
+		header["Content-Type"] = problemXMLContentType
+
+		body, err := xml.Marshal(problem)
+		if err != nil {
+			return err
+		}
+
+		return ctx.RenderBytes(problem.Status, body)
+	}
+
+	header["Content-Type"] = problemJSONContentType
+
+	body, err := json.Marshal(problem)
+	if err != nil {
+		return err
+	}
+
+	return ctx.RenderBytes(problem.Status, body)
+}
+
 // Returns an error if any binding errors occur with object, does not enforce any behavior.
+// Forms are parsed with the MaxMultipartMemory and MaxFileSize of the Mux before they are bound.
+// The object is validated with the Validator of the Mux, if the Binder is a binding.Decoder.
+// Requests that could not be decoded, such as malformed json, give a status.BadRequest.
 func (ctx *Ctx) ShouldBindWith(obj any, binder binding.Binder) error {
-	return binder.Bind(ctx.request, obj)
+	if _, ok := binder.(binding.Decoder); !ok {
+		return ctx.decodeWith(obj, binder)
+	}
+
+	if err := ctx.decodeWith(obj, binder); err != nil {
+		return err
+	}
+
+	return ctx.validate(obj)
+}
+
+// Validates an object reference with the Validator of the Mux, or binding.Validator if it has none.
+func (ctx *Ctx) validate(obj any) error {
+	if ctx.mux == nil || ctx.mux.validator == nil {
+		return binding.Validate(obj)
+	}
+
+	return binding.ValidateWith(ctx.mux.validator, obj)
+}
+
+// Parses the form of the request before it is bound, if the Binder binds forms.
+// Returns an error.PayloadTooLarge if the body is too large, otherwise an error.BadRequest if it is malformed.
+func (ctx *Ctx) parseForm(binder binding.Binder) error {
+	switch binder {
+	case binding.FORM:
+		if err := ctx.request.ParseForm(); err != nil {
+			return decodeError(err)
+		}
+
+		mediaType, _, err := mime.ParseMediaType(ctx.request.Header.Get("Content-Type"))
+		if err != nil || mediaType != "multipart/form-data" {
+			return nil
+		}
+
+		return decodeError(ctx.parseMultipartForm())
+	case binding.MULTIPART:
+		return decodeError(ctx.parseMultipartForm())
+	}
+
+	return nil
+}
+
+// Binds an object reference with a Binder, without validating it if the Binder is a binding.Decoder.
+func (ctx *Ctx) decodeWith(obj any, binder binding.Binder) error {
+	if err := ctx.parseForm(binder); err != nil {
+		return err
+	}
+
+	decoder, ok := binder.(binding.Decoder)
+	if !ok {
+		return decodeError(binder.Bind(ctx.request, obj))
+	}
+
+	return decodeError(decoder.Decode(ctx.request, obj))
+}
+
+// Wraps an error given when a request could not be decoded as a status.BadRequest.
+// Errors that already have a status, such as a *http.MaxBytesError or binding.FieldErrors, are kept.
+func decodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var e *amperr.Error
+	if errors.As(err, &e) {
+		return err
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return err
+	}
+
+	return amperr.Wrap(err, status.BadRequest, "malformed request")
 }
 
 // Enforces that an object has bound, otherwise an error is returned and the context is aborted.
@@ -451,6 +1168,35 @@ func (ctx *Ctx) MustBindWith(obj any, binder binding.Binder) error {
 	return nil
 }
 
+// Bind an object reference to the body of the request, using the Binder for its Content-Type.
+// Binders are found with binding.Lookup, so custom Binders can be added with binding.Register.
+// Returns an error.UnsupportedMediaType if there is no Binder for the Content-Type.
+func (ctx *Ctx) ShouldBind(obj any) error {
+	contentType := ctx.request.Header.Get("Content-Type")
+	if contentType == "" {
+		return amperr.UnsupportedMediaType("missing Content-Type")
+	}
+
+	binder, ok := binding.Lookup(contentType)
+	if !ok {
+		return amperr.UnsupportedMediaType(fmt.Sprintf("unsupported Content-Type %q", contentType))
+	}
+
+	return ctx.ShouldBindWith(obj, binder)
+}
+
+// Bind an object reference to the body of the request, using the Binder for its Content-Type.
+// Will abort if it fails to bind, or there is no Binder for the Content-Type.
+func (ctx *Ctx) Bind(obj any) error {
+	err := ctx.ShouldBind(obj)
+	if err != nil {
+		ctx.Abort()
+		return err
+	}
+
+	return nil
+}
+
 // Bind an object reference to some JSON.
 func (ctx *Ctx) ShouldBindJSON(obj any) error {
 	return ctx.ShouldBindWith(obj, binding.JSON)
@@ -490,3 +1236,120 @@ func (ctx *Ctx) ShouldBindXML(obj any) error {
 func (ctx *Ctx) BindXML(obj any) error {
 	return ctx.MustBindWith(obj, binding.XML)
 }
+
+// Bind an object reference to the query of the request, using query tags.
+func (ctx *Ctx) ShouldBindQuery(obj any) error {
+	return ctx.ShouldBindWith(obj, binding.QUERY)
+}
+
+// Bind an object reference to the query of the request, using query tags, will abort if it fails to bind.
+func (ctx *Ctx) BindQuery(obj any) error {
+	return ctx.MustBindWith(obj, binding.QUERY)
+}
+
+// Bind an object reference to the path params of the request, using uri tags.
+func (ctx *Ctx) ShouldBindURI(obj any) error {
+	return ctx.ShouldBindWith(obj, binding.URI)
+}
+
+// Bind an object reference to the path params of the request, using uri tags, will abort if it fails to bind.
+func (ctx *Ctx) BindURI(obj any) error {
+	return ctx.MustBindWith(obj, binding.URI)
+}
+
+// Bind an object reference to the headers of the request, using header tags.
+func (ctx *Ctx) ShouldBindHeader(obj any) error {
+	return ctx.ShouldBindWith(obj, binding.HEADER)
+}
+
+// Bind an object reference to the headers of the request, using header tags, will abort if it fails to bind.
+func (ctx *Ctx) BindHeader(obj any) error {
+	return ctx.MustBindWith(obj, binding.HEADER)
+}
+
+// Bind an object reference to the cookies of the request, using cookie tags.
+func (ctx *Ctx) ShouldBindCookie(obj any) error {
+	return ctx.ShouldBindWith(obj, binding.COOKIE)
+}
+
+// Bind an object reference to the cookies of the request, using cookie tags, will abort if it fails to bind.
+func (ctx *Ctx) BindCookie(obj any) error {
+	return ctx.MustBindWith(obj, binding.COOKIE)
+}
+
+// Bind an object reference to the form of the request, url encoded or multipart, using form tags.
+func (ctx *Ctx) ShouldBindForm(obj any) error {
+	return ctx.ShouldBindWith(obj, binding.FORM)
+}
+
+// Bind an object reference to the form of the request, url encoded or multipart, using form tags, will abort if it fails to bind.
+func (ctx *Ctx) BindForm(obj any) error {
+	return ctx.MustBindWith(obj, binding.FORM)
+}
+
+// Bind an object reference to the multipart form of the request, including its files, using form tags.
+func (ctx *Ctx) ShouldBindMultipart(obj any) error {
+	return ctx.ShouldBindWith(obj, binding.MULTIPART)
+}
+
+// Bind an object reference to the multipart form of the request, including its files, using form tags, will abort if it fails to bind.
+func (ctx *Ctx) BindMultipart(obj any) error {
+	return ctx.MustBindWith(obj, binding.MULTIPART)
+}
+
+// Bind an object reference to the body, query, headers, cookies and path params of the request.
+// The body is bound with the Binder for its Content-Type, if the request has one,
+// then the query, headers, cookies and path params are bound using query, header, cookie and uri tags.
+// Later sources take precedence, so a path param is not replaced by a field of the body.
+//
+//	type UpdateUser struct {
+//		ID    int    `uri:"id"`
+//		Force bool   `query:"force"`
+//		Name  string `json:"name" binding:"required"`
+//	}
+//
+// Each source is bound without validation, the object is then validated once, after all sources.
+// Errors of a source are given as a *binding.BindError, joined if more than one source failed.
+func (ctx *Ctx) ShouldBindAll(obj any) error {
+	errs := make([]error, 0)
+
+	if ctx.request.Body != nil && ctx.request.Body != http.NoBody {
+		contentType := ctx.request.Header.Get("Content-Type")
+
+		binder, ok := binding.Lookup(contentType)
+		switch {
+		case contentType == "":
+			errs = append(errs, &binding.BindError{Source: "body", Err: amperr.UnsupportedMediaType("missing Content-Type")})
+		case !ok:
+			errs = append(errs, &binding.BindError{Source: "body", Err: amperr.UnsupportedMediaType(fmt.Sprintf("unsupported Content-Type %q", contentType))})
+		default:
+			if err := ctx.decodeWith(obj, binder); err != nil {
+				errs = append(errs, &binding.BindError{Source: binder.Name(), Err: err})
+			}
+		}
+	}
+
+	for _, binder := range []binding.Binder{binding.QUERY, binding.HEADER, binding.COOKIE, binding.URI} {
+		if err := ctx.decodeWith(obj, binder); err != nil {
+			errs = append(errs, &binding.BindError{Source: binder.Name(), Err: err})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return ctx.validate(obj)
+}
+
+// Bind an object reference to the body, query, headers, cookies and path params of the request,
+// like ShouldBindAll, will abort if it fails to bind.
+func (ctx *Ctx) BindAll(obj any) error {
+	err := ctx.ShouldBindAll(obj)
+	if err != nil {
+		ctx.Abort()
+		return err
+	}
+
+	return nil
+}
@@ -1,11 +1,20 @@
 package amp
 
 import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/joseph-beck/amp/pkg/binding"
+	amperr "github.com/joseph-beck/amp/pkg/error"
 	"github.com/joseph-beck/amp/pkg/status"
 	"github.com/stretchr/testify/assert"
 )
@@ -1016,3 +1025,912 @@ func TestCtxBindXML(t *testing.T) {
 	writer = httptest.NewRecorder()
 	amp.ServeHTTP(writer, request)
 }
+
+func TestCtxBind(t *testing.T) {
+	amp := New()
+
+	amp.Post("/test", func(ctx *Ctx) error {
+		var obj Mock
+		err := ctx.Bind(&obj)
+		if err != nil {
+			return err
+		}
+
+		return ctx.Render(status.OK, obj.Key)
+	})
+
+	for contentType, body := range map[string]string{
+		"application/json; charset=utf-8": `{"key": "json"}`,
+		"application/merge-patch+json":    `{"key": "json"}`,
+		"application/xml":                 `<root><key>xml</key></root>`,
+		"application/x-yaml":              `key: yaml`,
+		"application/toml":                `key = "toml"`,
+	} {
+		request := httptest.NewRequest("POST", "/test", strings.NewReader(body))
+		request.Header.Set("Content-Type", contentType)
+		writer := httptest.NewRecorder()
+		amp.ServeHTTP(writer, request)
+		assert.Equal(t, status.OK, writer.Code, contentType)
+		assert.NotEmpty(t, writer.Body.String(), contentType)
+	}
+
+	request := httptest.NewRequest("POST", "/test", strings.NewReader(`key,value`))
+	request.Header.Set("Content-Type", "text/csv")
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.UnsupportedMediaType, writer.Code)
+
+	request = httptest.NewRequest("POST", "/test", strings.NewReader(`{"key": "json"}`))
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.UnsupportedMediaType, writer.Code)
+}
+
+func TestCtxBindSources(t *testing.T) {
+	amp := New()
+
+	type request struct {
+		ID      int      `uri:"id"`
+		Tags    []string `query:"tag"`
+		Page    int      `query:"page" default:"1"`
+		Token   string   `header:"X-Token" binding:"required"`
+		Session string   `cookie:"session"`
+	}
+
+	amp.Get("/test/{id}", func(ctx *Ctx) error {
+		// validation runs for every source, so the header must be bound first.
+		assert.Error(t, ctx.ShouldBindQuery(&request{}))
+
+		var obj request
+		assert.NoError(t, ctx.BindHeader(&obj))
+		assert.NoError(t, ctx.BindURI(&obj))
+		assert.NoError(t, ctx.BindQuery(&obj))
+		assert.NoError(t, ctx.BindCookie(&obj))
+		assert.Equal(t, request{ID: 1, Tags: []string{"a", "b"}, Page: 1, Token: "token", Session: "session"}, obj)
+
+		return nil
+	})
+
+	amp.Get("/fail/{id}", func(ctx *Ctx) error {
+		var obj request
+		err := ctx.BindURI(&obj)
+		assert.Error(t, err)
+		assert.True(t, ctx.Aborted())
+
+		return nil
+	})
+
+	req := httptest.NewRequest("GET", "/test/1?tag=a&tag=b", nil)
+	req.Header.Set("X-Token", "token")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "session"})
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, req)
+
+	req = httptest.NewRequest("GET", "/fail/one", nil)
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, req)
+}
+
+func TestCtxBindAll(t *testing.T) {
+	amp := New()
+
+	type request struct {
+		ID    int    `uri:"id" json:"id"`
+		Force bool   `query:"force"`
+		Token string `header:"X-Token" binding:"required"`
+		Name  string `json:"name" binding:"required"`
+	}
+
+	amp.Patch("/test/{id}", func(ctx *Ctx) error {
+		var obj request
+		err := ctx.BindAll(&obj)
+		if err != nil {
+			return err
+		}
+
+		return ctx.RenderJSON(status.OK, obj)
+	})
+
+	req := httptest.NewRequest("PATCH", "/test/1?force=true", strings.NewReader(`{"id": 2, "name": "value"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Token", "token")
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, req)
+	assert.Equal(t, status.OK, writer.Code)
+	assert.JSONEq(t, `{"id": 1, "Force": true, "Token": "token", "name": "value"}`, writer.Body.String())
+
+	// validation runs once, after every source, so the header is not required by the body.
+	req = httptest.NewRequest("PATCH", "/test/1", strings.NewReader(`{"name": "value"}`))
+	req.Header.Set("Content-Type", "application/json")
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, req)
+	assert.Equal(t, status.UnprocessableContent, writer.Code)
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Unprocessable Entity",
+		"status": 422,
+		"detail": "validation failed",
+		"instance": "/test/1",
+		"errors": [{"field": "Token", "message": "is required", "code": "required"}]
+	}`, writer.Body.String())
+
+	// defaults do not replace values bound from an earlier source.
+	amp.Post("/defaults", func(ctx *Ctx) error {
+		var obj struct {
+			Page int `json:"page" query:"page" default:"1"`
+			Size int `json:"size" query:"size" default:"10"`
+		}
+
+		if err := ctx.BindAll(&obj); err != nil {
+			return err
+		}
+
+		return ctx.RenderJSON(status.OK, obj)
+	})
+
+	req = httptest.NewRequest("POST", "/defaults", strings.NewReader(`{"page": 5}`))
+	req.Header.Set("Content-Type", "application/json")
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, req)
+	assert.Equal(t, status.OK, writer.Code)
+	assert.JSONEq(t, `{"page": 5, "size": 10}`, writer.Body.String())
+
+	amp.Get("/errors/{id}", func(ctx *Ctx) error {
+		var obj request
+		err := ctx.ShouldBindAll(&obj)
+		assert.Error(t, err)
+
+		var bindErr *binding.BindError
+		assert.ErrorAs(t, err, &bindErr)
+		assert.Equal(t, "json", bindErr.Source)
+		assert.Contains(t, err.Error(), "could not bind query")
+		assert.Contains(t, err.Error(), "could not bind uri")
+
+		return nil
+	})
+
+	req = httptest.NewRequest("GET", "/errors/one?force=maybe", strings.NewReader(`{"name":`))
+	req.Header.Set("Content-Type", "application/json")
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, req)
+	assert.Equal(t, status.OK, writer.Code)
+
+	// values that could not be converted are a bad request, with the source and field as a detail.
+	req = httptest.NewRequest("PATCH", "/test/1?force=maybe", strings.NewReader(`{"name": "value"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Token", "token")
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, req)
+	assert.Equal(t, status.BadRequest, writer.Code)
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Bad Request",
+		"status": 400,
+		"detail": "could not bind query \"force\"",
+		"instance": "/test/1",
+		"errors": [{"field": "force", "message": "is invalid", "code": "query"}]
+	}`, writer.Body.String())
+
+	req = httptest.NewRequest("PATCH", "/test/1", strings.NewReader(`key,value`))
+	req.Header.Set("Content-Type", "text/csv")
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, req)
+	assert.Equal(t, status.UnsupportedMediaType, writer.Code)
+}
+
+func TestCtxLimitBody(t *testing.T) {
+	cfg := Default()
+	cfg.MaxBodySize = 20
+	amp := New(cfg)
+
+	handler := func(ctx *Ctx) error {
+		var obj Mock
+		if err := ctx.Bind(&obj); err != nil {
+			return err
+		}
+
+		return ctx.Render(status.OK, obj.Key)
+	}
+
+	amp.Post("/test", handler)
+	amp.Post("/raised", handler, BodyLimit(1024))
+	amp.Post("/lowered", handler, BodyLimit(4))
+	amp.Post("/removed", handler, BodyLimit(0))
+
+	tests := []struct {
+		path   string
+		body   string
+		status int
+	}{
+		{"/test", `{"key": "value"}`, status.OK},
+		{"/test", `{"key": "a much longer value"}`, status.PayloadTooLarge},
+		{"/raised", `{"key": "a much longer value"}`, status.OK},
+		{"/lowered", `{"key": "value"}`, status.PayloadTooLarge},
+		{"/removed", `{"key": "a much longer value"}`, status.OK},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("POST", test.path, strings.NewReader(test.body))
+		req.Header.Set("Content-Type", "application/json")
+		writer := httptest.NewRecorder()
+		amp.ServeHTTP(writer, req)
+		assert.Equal(t, test.status, writer.Code, test.path)
+	}
+
+	// url encoded forms that are too large, or malformed, are not partially bound.
+	amp.Post("/form", func(ctx *Ctx) error {
+		var obj Mock
+		if err := ctx.BindForm(&obj); err != nil {
+			return err
+		}
+
+		return ctx.Render(status.OK, obj.Key)
+	})
+
+	forms := []struct {
+		body   string
+		status int
+	}{
+		{"key=value", status.OK},
+		{"key=value&other=a+much+longer+value", status.PayloadTooLarge},
+		{"key=%zz", status.BadRequest},
+	}
+
+	for _, form := range forms {
+		req := httptest.NewRequest("POST", "/form", strings.NewReader(form.body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		writer := httptest.NewRecorder()
+		amp.ServeHTTP(writer, req)
+		assert.Equal(t, form.status, writer.Code, form.body)
+	}
+}
+
+func TestCtxBindStrict(t *testing.T) {
+	amp := New()
+
+	amp.Post("/test", func(ctx *Ctx) error {
+		var obj Mock
+		err := ctx.ShouldBindWith(&obj, binding.JSON)
+		assert.NoError(t, err)
+
+		ctx.request.Body = io.NopCloser(strings.NewReader(`{"key": "value", "other": "field"}`))
+		err = ctx.ShouldBindWith(&obj, binding.JSON.Strict())
+		assert.ErrorContains(t, err, "unknown field")
+
+		return nil
+	})
+
+	req := httptest.NewRequest("POST", "/test", strings.NewReader(`{"key": "value", "other": "field"}`))
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, req)
+	assert.Equal(t, status.OK, writer.Code)
+
+	// strict binders can be used for a single route, without registering them.
+	tests := []struct {
+		binder binding.Binder
+		body   string
+	}{
+		{binding.StrictJSON, `{"key": "value", "other": "field"}`},
+		{binding.StrictTOML, "key = \"value\"\nother = \"field\""},
+		{binding.StrictYAML, "key: value\nother: field"},
+	}
+
+	for _, test := range tests {
+		amp.Post("/strict/"+test.binder.Name(), func(ctx *Ctx) error {
+			var obj Mock
+			if err := ctx.MustBindWith(&obj, test.binder); err != nil {
+				return err
+			}
+
+			return ctx.Render(status.OK, obj.Key)
+		})
+
+		req = httptest.NewRequest("POST", "/strict/"+test.binder.Name(), strings.NewReader(test.body))
+		writer = httptest.NewRecorder()
+		amp.ServeHTTP(writer, req)
+		assert.Equal(t, status.BadRequest, writer.Code, test.binder.Name())
+	}
+
+	// the registered binders are not strict.
+	binder, ok := binding.Lookup("application/json")
+	assert.True(t, ok)
+	assert.Equal(t, binding.JSON, binder)
+}
+
+func TestCtxBindForm(t *testing.T) {
+	amp := New()
+
+	type request struct {
+		Name   string                  `form:"name" binding:"required"`
+		Page   int                     `form:"page" default:"1"`
+		Avatar *multipart.FileHeader   `form:"avatar"`
+		Photos []*multipart.FileHeader `form:"photos"`
+	}
+
+	amp.Post("/form", func(ctx *Ctx) error {
+		var obj request
+		assert.NoError(t, ctx.BindForm(&obj))
+		assert.Equal(t, request{Name: "value", Page: 2}, obj)
+
+		return nil
+	})
+
+	amp.Post("/multipart", func(ctx *Ctx) error {
+		var obj request
+		assert.NoError(t, ctx.Bind(&obj))
+		assert.Equal(t, "value", obj.Name)
+		assert.Equal(t, 1, obj.Page)
+		assert.Equal(t, "avatar.txt", obj.Avatar.Filename)
+		assert.Len(t, obj.Photos, 2)
+
+		return nil
+	})
+
+	req := httptest.NewRequest("POST", "/form?page=2", strings.NewReader("name=value"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, req)
+	assert.Equal(t, status.OK, writer.Code)
+
+	body, contentType := newMultipartBody(t, map[string]string{"avatar": "avatar", "photos": "one"}, "photos")
+	req = httptest.NewRequest("POST", "/multipart", body)
+	req.Header.Set("Content-Type", contentType)
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, req)
+	assert.Equal(t, status.OK, writer.Code)
+}
+
+func TestCtxFormFile(t *testing.T) {
+	cfg := Default()
+	cfg.MaxFileSize = 8
+	amp := New(cfg)
+
+	dir := t.TempDir()
+
+	amp.Post("/test", func(ctx *Ctx) error {
+		file, err := ctx.FormFile("avatar")
+		if err != nil {
+			return err
+		}
+
+		_, err = ctx.FormFile("missing")
+		assert.Error(t, err)
+
+		return ctx.SaveUploadedFile(file, filepath.Join(dir, "uploads", file.Filename))
+	})
+
+	body, contentType := newMultipartBody(t, map[string]string{"avatar": "avatar"})
+	req := httptest.NewRequest("POST", "/test", body)
+	req.Header.Set("Content-Type", contentType)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, req)
+	assert.Equal(t, status.OK, writer.Code)
+
+	content, err := os.ReadFile(filepath.Join(dir, "uploads", "avatar.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "avatar", string(content))
+
+	body, contentType = newMultipartBody(t, map[string]string{"avatar": "too large avatar"})
+	req = httptest.NewRequest("POST", "/test", body)
+	req.Header.Set("Content-Type", contentType)
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, req)
+	assert.Equal(t, status.PayloadTooLarge, writer.Code)
+
+	req = httptest.NewRequest("POST", "/test", strings.NewReader("avatar=avatar"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, req)
+	assert.Equal(t, status.InternalServerError, writer.Code)
+}
+
+// Creates a multipart body with a field, "name", and a file for each of the given files.
+// Files given in extra are added a second time.
+func TestCtxFormFileRemoved(t *testing.T) {
+	cfg := Default()
+	cfg.MaxMultipartMemory = 1
+	amp := New(cfg)
+
+	var name string
+	amp.Post("/test", func(ctx *Ctx) error {
+		ctx.WithContext(context.WithValue(ctx, ctxKey{}, "value"))
+
+		fh, err := ctx.FormFile("avatar")
+		if err != nil {
+			return err
+		}
+
+		file, err := fh.Open()
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		// files larger than the MaxMultipartMemory are stored on disk.
+		osFile, ok := file.(*os.File)
+		assert.True(t, ok)
+		name = osFile.Name()
+
+		return nil
+	})
+
+	body, contentType := newMultipartBody(t, map[string]string{"avatar": "avatar"})
+	req := httptest.NewRequest("POST", "/test", body)
+	req.Header.Set("Content-Type", contentType)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, req)
+	assert.Equal(t, status.OK, writer.Code)
+
+	// the files are removed once the handlers have finished, even though the request was replaced.
+	assert.NotEmpty(t, name)
+	_, err := os.Stat(name)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func newMultipartBody(t *testing.T, files map[string]string, extra ...string) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	assert.NoError(t, writer.WriteField("name", "value"))
+
+	for key, content := range files {
+		part, err := writer.CreateFormFile(key, key+".txt")
+		assert.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+
+	for _, key := range extra {
+		part, err := writer.CreateFormFile(key, key+".txt")
+		assert.NoError(t, err)
+		_, err = part.Write([]byte(files[key]))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, writer.Close())
+	return body, writer.FormDataContentType()
+}
+
+func TestCtxRenderProblem(t *testing.T) {
+	amp := New()
+
+	amp.Get("/test/one", func(ctx *Ctx) error {
+		err := ctx.RenderProblem(amperr.BadRequest("invalid").WithDetail("name", "is required", "required"))
+		assert.NoError(t, err)
+		assert.Equal(t, status.BadRequest, ctx.status)
+
+		return nil
+	})
+
+	request := httptest.NewRequest("GET", "/test/one", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.BadRequest, writer.Code)
+	assert.Equal(t, problemJSONContentType[0], writer.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Bad Request",
+		"status": 400,
+		"detail": "invalid",
+		"instance": "/test/one",
+		"errors": [{"field": "name", "message": "is required", "code": "required"}]
+	}`, writer.Body.String())
+}
+
+func TestCtxNextChain(t *testing.T) {
+	amp := New()
+
+	order := make([]string, 0)
+
+	amp.Use(func(ctx *Ctx) error {
+		order = append(order, "one")
+		err := ctx.Next()
+		order = append(order, "one after")
+		return err
+	})
+
+	amp.Get(
+		"/test/one",
+		func(ctx *Ctx) error {
+			order = append(order, "handler")
+			return nil
+		},
+		func(ctx *Ctx) error {
+			order = append(order, "two")
+			return nil
+		},
+	)
+
+	request := httptest.NewRequest("GET", "/test/one", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, []string{"one", "two", "handler", "one after"}, order)
+
+	order = make([]string, 0)
+
+	amp.Get(
+		"/test/two",
+		func(ctx *Ctx) error {
+			order = append(order, "handler")
+			return nil
+		},
+		func(ctx *Ctx) error {
+			order = append(order, "abort")
+			ctx.AbortWithStatus(status.Unauthorized)
+			return nil
+		},
+	)
+
+	request = httptest.NewRequest("GET", "/test/two", nil)
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, []string{"one", "abort", "one after"}, order)
+	assert.Equal(t, status.Unauthorized, writer.Code)
+}
+
+func TestCtxWritten(t *testing.T) {
+	amp := New()
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		ctx.Status(status.Created)
+		assert.False(t, ctx.Written())
+
+		ctx.Header("X-After-Status", "value")
+
+		err := ctx.Render(status.Accepted, "body")
+		assert.NoError(t, err)
+		assert.True(t, ctx.Written())
+		assert.Equal(t, 4, ctx.Size())
+
+		return nil
+	}, func(ctx *Ctx) error {
+		err := ctx.Next()
+		ctx.Header("X-After-Write", "value")
+		return err
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.Accepted, writer.Code)
+	assert.Equal(t, "value", writer.Header().Get("X-After-Status"))
+	assert.Equal(t, "", writer.Result().Header.Get("X-After-Write"))
+	assert.Equal(t, "body", writer.Body.String())
+}
+
+func TestCtxSize(t *testing.T) {
+	amp := New()
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		assert.Equal(t, 0, ctx.Size())
+
+		_, err := ctx.Write("body")
+		assert.NoError(t, err)
+		assert.Equal(t, 4, ctx.Size())
+
+		return nil
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+}
+
+func TestCtxHeaderAfterNext(t *testing.T) {
+	amp := New()
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		ctx.Status(status.Created)
+		return nil
+	}, func(ctx *Ctx) error {
+		err := ctx.Next()
+		ctx.Header("X-After", "value")
+		return err
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.Created, writer.Code)
+	assert.Equal(t, "value", writer.Header().Get("X-After"))
+}
+
+func TestCtxReset(t *testing.T) {
+	ctx := newCtx(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+	ctx.Set("key", "value")
+	ctx.AbortWithStatus(status.BadRequest)
+	ctx.handlers = []Handler{func(ctx *Ctx) error { return nil }}
+	ctx.index = 0
+
+	writer := httptest.NewRecorder()
+	request := httptest.NewRequest("GET", "/other", nil)
+	ctx.reset(writer, request)
+
+	assert.Equal(t, request, ctx.request)
+	assert.Equal(t, status.OK, ctx.status)
+	assert.False(t, ctx.aborted)
+	assert.Empty(t, ctx.values)
+	assert.Nil(t, ctx.handlers)
+	assert.Equal(t, -1, ctx.index)
+	assert.Equal(t, writer, ctx.response.ResponseWriter)
+	assert.False(t, ctx.Written())
+	assert.Equal(t, 0, ctx.Size())
+}
+
+type ctxKey struct{}
+
+type derivedKey struct{}
+
+func TestCtxContext(t *testing.T) {
+	amp := New()
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		var c context.Context = ctx
+
+		_, ok := c.Deadline()
+		assert.False(t, ok)
+		assert.NoError(t, c.Err())
+
+		ctx.Set("key", "value")
+		assert.Equal(t, "value", c.Value("key"))
+		assert.Equal(t, "request", c.Value(ctxKey{}))
+		assert.Nil(t, c.Value("missing"))
+		assert.Nil(t, c.Value(1))
+
+		return nil
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	request = request.WithContext(context.WithValue(request.Context(), ctxKey{}, "request"))
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+}
+
+func TestCtxContextCancel(t *testing.T) {
+	amp := New()
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		select {
+		case <-ctx.Done():
+		default:
+			t.Fatal("ctx should be done")
+		}
+
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+
+		return nil
+	})
+
+	c, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	request := httptest.NewRequestWithContext(c, "GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+}
+
+func TestCtxWithContext(t *testing.T) {
+	amp := New()
+
+	deadline := time.Now().Add(1 * time.Minute)
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		d, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.Equal(t, deadline, d)
+		assert.Equal(t, "middleware", ctx.Value(ctxKey{}))
+		assert.Equal(t, "middleware", ctx.Request().Context().Value(ctxKey{}))
+
+		return nil
+	}, func(ctx *Ctx) error {
+		c, cancel := context.WithDeadline(ctx.Request().Context(), deadline)
+		defer cancel()
+
+		ctx.WithContext(context.WithValue(c, ctxKey{}, "middleware"))
+		return ctx.Next()
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+
+	// contexts derived from the Ctx itself can also be given.
+	amp.Get("/derived", func(ctx *Ctx) error {
+		d, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(50*time.Millisecond), d, 50*time.Millisecond)
+		assert.NoError(t, ctx.Err())
+		assert.Equal(t, "request", ctx.Value(ctxKey{}))
+		assert.Equal(t, "derived", ctx.Value(derivedKey{}))
+		assert.Equal(t, "derived", ctx.Request().Context().Value(derivedKey{}))
+		assert.Nil(t, ctx.Value("missing"))
+
+		ctx.Set("key", "value")
+		assert.Equal(t, "value", ctx.Request().Context().Value("key"))
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(1 * time.Second):
+			t.Fatal("ctx should be done after the timeout")
+		}
+
+		assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+		assert.ErrorIs(t, ctx.Request().Context().Err(), context.DeadlineExceeded)
+
+		return nil
+	}, func(ctx *Ctx) error {
+		c, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		ctx.WithContext(c)
+
+		// values set on a context derived from the Ctx are read back from the Ctx.
+		ctx.WithContext(context.WithValue(ctx, derivedKey{}, "derived"))
+		assert.Equal(t, "derived", ctx.Value(derivedKey{}))
+		return ctx.Next()
+	})
+
+	request = httptest.NewRequest("GET", "/derived", nil)
+	request = request.WithContext(context.WithValue(request.Context(), ctxKey{}, "request"))
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.OK, writer.Code)
+}
+
+func TestCtxSkip(t *testing.T) {
+	amp := New()
+
+	order := make([]string, 0)
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		order = append(order, "handler")
+		return nil
+	}, func(ctx *Ctx) error {
+		order = append(order, "skip")
+		ctx.Skip()
+		return nil
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, []string{"skip"}, order)
+	assert.Equal(t, status.OK, writer.Code)
+}
+
+func TestCtxCopy(t *testing.T) {
+	amp := New()
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		ctx.Set("handler", true)
+		ctx.Header("X-Copy", "true")
+		return ctx.Render(status.Created, "copy")
+	}, func(ctx *Ctx) error {
+		ctx.Set("key", "value")
+		ctx.Header("X-Original", "true")
+
+		cp := ctx.Copy()
+		val, err := cp.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "value", val)
+		assert.Equal(t, "true", cp.Writer().Header().Get("X-Original"))
+
+		err = cp.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, status.Created, cp.GetStatus())
+		assert.Equal(t, 4, cp.Size())
+
+		_, err = ctx.Get("handler")
+		assert.Error(t, err)
+		assert.Equal(t, "", ctx.Writer().Header().Get("X-Copy"))
+
+		ctx.Skip()
+		return nil
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.OK, writer.Code)
+	assert.Equal(t, "", writer.Body.String())
+	assert.Equal(t, "true", writer.Header().Get("X-Original"))
+}
+
+func TestCtxRequestID(t *testing.T) {
+	request := httptest.NewRequest("GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	ctx := newCtx(writer, request)
+	assert.Equal(t, "", ctx.RequestID())
+	assert.Nil(t, ctx.logAttrs())
+
+	ctx.SetRequestID("id")
+	assert.Equal(t, "id", ctx.RequestID())
+	assert.Equal(t, "id", ctx.Copy().RequestID())
+	assert.Equal(t, []any{"request_id", "id"}, ctx.logAttrs())
+
+	ctx.reset(writer, request)
+	assert.Equal(t, "", ctx.RequestID())
+}
+
+func TestCtxClientIP(t *testing.T) {
+	amp := New(Config{
+		TrustedProxies: []string{"10.0.0.0/8"},
+	})
+
+	var ip, scheme, host string
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		ip = ctx.ClientIP()
+		scheme = ctx.Scheme()
+		host = ctx.Host()
+		return nil
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	request.RemoteAddr = "10.0.0.1:1234"
+	request.Header.Set("X-Forwarded-For", "203.0.113.7")
+	request.Header.Set("X-Forwarded-Proto", "https")
+	request.Header.Set("X-Forwarded-Host", "example.com")
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, "203.0.113.7", ip)
+	assert.Equal(t, "https", scheme)
+	assert.Equal(t, "example.com", host)
+
+	// headers from clients that are not trusted are ignored.
+	request = httptest.NewRequest("GET", "/test", nil)
+	request.RemoteAddr = "198.51.100.1:1234"
+	request.Header.Set("X-Forwarded-For", "203.0.113.7")
+	request.Header.Set("X-Forwarded-Proto", "https")
+	request.Header.Set("X-Forwarded-Host", "forwarded.com")
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, "198.51.100.1", ip)
+	assert.Equal(t, "http", scheme)
+	assert.Equal(t, "example.com", host)
+
+}
+
+func TestCtxNegotiate(t *testing.T) {
+	amp := New()
+
+	type user struct {
+		Name string `json:"name" xml:"name" yaml:"name" toml:"name"`
+	}
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		return ctx.Negotiate(status.OK, user{Name: "amp"})
+	})
+
+	amp.Get("/offers", func(ctx *Ctx) error {
+		return ctx.Negotiate(status.OK, user{Name: "amp"}, "application/xml", "application/x-yaml")
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.OK, writer.Code)
+	assert.Equal(t, jsonContentType[0], writer.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept", writer.Header().Get("Vary"))
+	assert.Equal(t, `{"name":"amp"}`, writer.Body.String())
+
+	request = httptest.NewRequest("GET", "/test", nil)
+	request.Header.Set("Accept", "application/json;q=0.5, application/xml")
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, xmlContentType[0], writer.Header().Get("Content-Type"))
+	assert.Equal(t, "<user><name>amp</name></user>", writer.Body.String())
+
+	request = httptest.NewRequest("GET", "/offers", nil)
+	request.Header.Set("Accept", "application/json, application/*;q=0.5")
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, xmlContentType[0], writer.Header().Get("Content-Type"))
+
+	request = httptest.NewRequest("GET", "/offers", nil)
+	request.Header.Set("Accept", "text/html")
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.NotAcceptable, writer.Code)
+	assert.Equal(t, "Accept", writer.Header().Get("Vary"))
+	assert.Equal(t, problemJSONContentType[0], writer.Header().Get("Content-Type"))
+}
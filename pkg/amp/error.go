@@ -0,0 +1,31 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Amp is a web framework made using the Go 1.22 Mux.
+// Please ensure you are using Go 1.22, minimum, when using Amp.
+package amp
+
+import (
+	amperr "github.com/joseph-beck/amp/pkg/error"
+)
+
+// Amp ErrorHandler.
+// Called by the Mux whenever a Handler or middleware returns an error.
+// Responsible for turning the error into a response for the client.
+type ErrorHandler func(ctx *Ctx, err error)
+
+// The default ErrorHandler used by the Mux.
+// Renders the error as problem details, using Ctx.RenderProblem.
+// If the error is an *error.Error, its Status, Message, Code and Details are used in the response.
+// Any other error will result in a status.InternalServerError,
+// the message of unknown errors is not given to the client.
+// Nothing is rendered if the response has already been written.
+func DefaultErrorHandler(ctx *Ctx, err error) {
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.RenderProblem(err) != nil {
+		ctx.Status(amperr.From(err).Status)
+	}
+}
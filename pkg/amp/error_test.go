@@ -0,0 +1,108 @@
+package amp
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	amperr "github.com/joseph-beck/amp/pkg/error"
+	"github.com/joseph-beck/amp/pkg/status"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultErrorHandler(t *testing.T) {
+	amp := New()
+
+	amp.Get("/test/one", func(ctx *Ctx) error {
+		return amperr.NotFound("not here").WithCode("missing").WithHeader("X-Test", "value")
+	})
+
+	request := httptest.NewRequest("GET", "/test/one", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.NotFound, writer.Code)
+	assert.Equal(t, problemJSONContentType[0], writer.Header().Get("Content-Type"))
+	assert.Equal(t, "value", writer.Header().Get("X-Test"))
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Not Found",
+		"status": 404,
+		"detail": "not here",
+		"instance": "/test/one",
+		"code": "missing"
+	}`, writer.Body.String())
+
+	request = httptest.NewRequest("GET", "/test/one", nil)
+	request.Header.Set("Accept", "application/xml")
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.NotFound, writer.Code)
+	assert.Equal(t, problemXMLContentType[0], writer.Header().Get("Content-Type"))
+	assert.Contains(t, writer.Body.String(), `<detail>not here</detail>`)
+
+	// the quality of each media type of the Accept header is respected.
+	request = httptest.NewRequest("GET", "/test/one", nil)
+	request.Header.Set("Accept", "application/json;q=0.5, application/xml")
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, problemXMLContentType[0], writer.Header().Get("Content-Type"))
+
+	request = httptest.NewRequest("GET", "/test/one", nil)
+	request.Header.Set("Accept", "application/xml;q=0.5, application/json")
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, problemJSONContentType[0], writer.Header().Get("Content-Type"))
+
+	request = httptest.NewRequest("GET", "/test/one", nil)
+	request.Header.Set("Accept", "text/html")
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, problemJSONContentType[0], writer.Header().Get("Content-Type"))
+
+	amp.Get("/test/two", func(ctx *Ctx) error {
+		return errors.New("secret")
+	})
+
+	request = httptest.NewRequest("GET", "/test/two", nil)
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.InternalServerError, writer.Code)
+	assert.NotContains(t, writer.Body.String(), "secret")
+}
+
+func TestDefaultErrorHandlerWritten(t *testing.T) {
+	amp := New()
+
+	// errors returned once the response has been written do not change it.
+	amp.Get("/test", func(ctx *Ctx) error {
+		if err := ctx.Render(status.OK, "written"); err != nil {
+			return err
+		}
+
+		return amperr.BadRequest("too late")
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.OK, writer.Code)
+	assert.Equal(t, "written", writer.Body.String())
+}
+
+func TestCustomErrorHandler(t *testing.T) {
+	cfg := Default()
+	cfg.ErrorHandler = func(ctx *Ctx, err error) {
+		_ = ctx.Render(status.ImATeapot, err.Error())
+	}
+	amp := New(cfg)
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		return errors.New("custom")
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.ImATeapot, writer.Code)
+	assert.Equal(t, "custom", writer.Body.String())
+}
@@ -6,24 +6,28 @@
 package amp
 
 import (
-	"log/slog"
 	"net/http"
 )
 
 // Amp Handler.
 // Uses the *amp.Ctx.
 // Returns an error, will slog the error if one occurs during execution.
-// Centralised error handling within the Mux.
+// Centralised error handling within the Mux, using the ErrorHandler of the Mux.
 type Handler func(ctx *Ctx) error
 
 // Unwrap an amp.Handler into a net/http HandlerFunc.
+// Any error returned is handled by the DefaultErrorHandler.
 func (h Handler) Unwrap(fn Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := newCtx(w, r)
 		err := fn(ctx)
 		if err != nil {
-			slog.Error(err.Error())
+			ctx.HandleError(err)
 		}
+
+		// writes the status and headers if nothing else has, once the handler has finished.
+		ctx.response.writeHeaderNow()
+		ctx.removeMultipartForm()
 	}
 }
 
@@ -35,3 +39,14 @@ func Wrap(fn http.HandlerFunc) Handler {
 		return nil
 	}
 }
+
+// Middleware that limits the size of the body of requests, replacing the MaxBodySize of the Mux.
+// Bodies that are larger give an error.PayloadTooLarge, or a 413, when they are read, such as by Ctx.Bind.
+//
+//	a.Post("/upload", upload, amp.BodyLimit(64<<20))
+func BodyLimit(n int64) Handler {
+	return func(ctx *Ctx) error {
+		ctx.LimitBody(n)
+		return nil
+	}
+}
@@ -1 +1,70 @@
 package amp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joseph-beck/amp/pkg/status"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerUnwrap(t *testing.T) {
+	var h Handler
+
+	// the status is written once the handler returns, even without a body.
+	handler := h.Unwrap(func(ctx *Ctx) error {
+		ctx.Status(status.NoContent)
+		return nil
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	handler(writer, request)
+	assert.Equal(t, status.NoContent, writer.Code)
+	assert.Empty(t, writer.Body.String())
+
+	handler = h.Unwrap(func(ctx *Ctx) error {
+		return ctx.Render(status.Created, "created")
+	})
+
+	request = httptest.NewRequest("GET", "/test", nil)
+	writer = httptest.NewRecorder()
+	handler(writer, request)
+	assert.Equal(t, status.Created, writer.Code)
+	assert.Equal(t, "created", writer.Body.String())
+}
+
+func TestHandlerWrap(t *testing.T) {
+	amp := New()
+
+	statuses := make([]int, 0)
+	record := func(ctx *Ctx) error {
+		err := ctx.Next()
+		statuses = append(statuses, ctx.GetStatus())
+		return err
+	}
+
+	// the status written by a wrapped http.HandlerFunc is the status of the Ctx.
+	amp.Get("/body", Wrap(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status.NotFound)
+		_, _ = w.Write([]byte("missing"))
+	}), record)
+
+	amp.Get("/empty", Wrap(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status.Accepted)
+	}), record)
+
+	request := httptest.NewRequest("GET", "/body", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.NotFound, writer.Code)
+	assert.Equal(t, "missing", writer.Body.String())
+
+	request = httptest.NewRequest("GET", "/empty", nil)
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.Accepted, writer.Code)
+
+	assert.Equal(t, []int{status.NotFound, status.Accepted}, statuses)
+}
@@ -6,11 +6,21 @@
 package amp
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
-
+	"net/netip"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/joseph-beck/amp/pkg/binding"
+	amperr "github.com/joseph-beck/amp/pkg/error"
 	"github.com/joseph-beck/amp/pkg/status"
 )
 
@@ -25,6 +35,10 @@ ________  _____ ______   ________
     \|__|\|__|\|__|     \|__|\|__|   
 `
 
+// All methods that can be routed by the Mux.
+// Used when computing the Allow header of a status.MethodNotAllowed response.
+var methods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "CONNECT", "OPTIONS", "TRACE"}
+
 // Mux configs, allow you to customise the mux.
 // Used as an arg in the New(args ...config) function.
 type Config struct {
@@ -48,6 +62,76 @@ type Config struct {
 	// This is used when doing pre-flight checks etc.
 	// Please have this set to true if you want CORS policies to work.
 	DefaultOptions bool
+
+	// The maximum duration for reading the entire request, including the body.
+	// A zero or negative value means there will be no timeout.
+	ReadTimeout time.Duration
+
+	// The amount of time allowed to read request headers.
+	// If this is zero, the value of ReadTimeout is used.
+	ReadHeaderTimeout time.Duration
+
+	// The maximum duration before timing out writes of the response.
+	// A zero or negative value means there will be no timeout.
+	WriteTimeout time.Duration
+
+	// The maximum amount of time to wait for the next request when keep-alives are enabled.
+	// If this is zero, the value of ReadTimeout is used.
+	IdleTimeout time.Duration
+
+	// Controls the maximum number of bytes the server will read parsing the request header.
+	// If this is zero, http.DefaultMaxHeaderBytes is used.
+	MaxHeaderBytes int
+
+	// The maximum amount of time Run will wait for in-flight requests to drain,
+	// once an interrupt or terminate signal has been received.
+	// A zero value means Run will wait indefinitely.
+	ShutdownTimeout time.Duration
+
+	// Called whenever a Handler or middleware returns an error.
+	// Responsible for turning the error into a response.
+	// If this is nil, DefaultErrorHandler is used.
+	ErrorHandler ErrorHandler
+
+	// Logger used by the Mux, for routes, requests and errors.
+	// If this is nil, slog.Default() is used.
+	Logger *slog.Logger
+
+	// Stops the Mux from printing the logo, and logging routes and requests.
+	// Errors are still logged, use a Logger that discards them to silence them too.
+	// Often used along with the logger middleware.
+	Quiet bool
+
+	// Proxies that are trusted to give the client of a request, as CIDRs or addresses.
+	// The Forwarded, X-Forwarded-For and X-Real-IP headers are only used by Ctx.ClientIP,
+	// Ctx.Scheme and Ctx.Host when the request is from one of these.
+	// Invalid entries are logged and ignored.
+	//
+	//	TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1"}
+	TrustedProxies []string
+
+	// The maximum bytes of a multipart form that are kept in memory, the rest of its files are stored on disk.
+	// Used when binding forms and by Ctx.FormFile.
+	// If this is zero, binding.DefaultMaxMultipartMemory, 32 MB, is used.
+	MaxMultipartMemory int64
+
+	// The maximum size in bytes of each file of a multipart form.
+	// Larger files give an error.PayloadTooLarge when binding forms and from Ctx.FormFile.
+	// Files are checked once the form has been read, so this does not limit the memory or disk used to read it,
+	// use MaxBodySize or BodyLimit for that.
+	// A zero value means there is no limit.
+	MaxFileSize int64
+
+	// The maximum size in bytes of the body of a request.
+	// Reading a larger body, such as with Ctx.Bind, gives an error.PayloadTooLarge, or a 413.
+	// Can be replaced for a route with the BodyLimit middleware.
+	// A zero value means there is no limit.
+	MaxBodySize int64
+
+	// Validates objects bound by the Ctx, such as with Ctx.Bind.
+	// Give each Mux its own binding.NewValidator() to prevent sharing custom rules between them.
+	// If this is nil, binding.Validator is used.
+	Validator binding.StructValidator
 }
 
 // Gives a default config,
@@ -56,13 +140,41 @@ type Config struct {
 // CRT: "",
 // Key: "",
 // DefaultOptions: true,
+// ReadTimeout: 0,
+// ReadHeaderTimeout: 10 * time.Second,
+// WriteTimeout: 0,
+// IdleTimeout: 2 * time.Minute,
+// MaxHeaderBytes: 0,
+// ShutdownTimeout: 10 * time.Second,
+// ErrorHandler: DefaultErrorHandler,
+// Logger: nil,
+// Quiet: false,
+// TrustedProxies: nil,
+// MaxMultipartMemory: 32 << 20,
+// MaxFileSize: 0,
+// MaxBodySize: 0,
+// Validator: nil,
 func Default() Config {
 	return Config{
-		Port:           8080,
-		Host:           "",
-		CRT:            "",
-		Key:            "",
-		DefaultOptions: true,
+		Port:               8080,
+		Host:               "",
+		CRT:                "",
+		Key:                "",
+		DefaultOptions:     true,
+		ReadTimeout:        0,
+		ReadHeaderTimeout:  10 * time.Second,
+		WriteTimeout:       0,
+		IdleTimeout:        2 * time.Minute,
+		MaxHeaderBytes:     0,
+		ShutdownTimeout:    10 * time.Second,
+		ErrorHandler:       DefaultErrorHandler,
+		Logger:             nil,
+		Quiet:              false,
+		TrustedProxies:     nil,
+		MaxMultipartMemory: binding.DefaultMaxMultipartMemory,
+		MaxFileSize:        0,
+		MaxBodySize:        0,
+		Validator:          nil,
 	}
 }
 
@@ -70,10 +182,19 @@ func Default() Config {
 // Use Get, Post, etc. to add new methods.
 // To run use log.Fatalln(x.ListenAndServe()).
 // Can use HTTPS with ListenAndServeTLS().
+// Use x.Run() to stop gracefully on an interrupt or terminate signal.
 type Mux struct {
-	// Go net/http serverMux, used as the server.
+	// Go net/http serverMux, used for routing.
 	mux *http.ServeMux
 
+	// Go net/http server, owned by the Mux.
+	// Configured with the timeouts given in the Config,
+	// this is used when serving and shutting down the Mux.
+	server *http.Server
+
+	// Time Run will wait for in-flight requests to drain.
+	shutdownTimeout time.Duration
+
 	// Port used by the Mux.
 	// Program will exit if the port is already bound.
 	port uint
@@ -95,6 +216,54 @@ type Mux struct {
 	// Please have this set to true if you want CORS policies to work.
 	defaultOptions bool
 
+	// Turns errors returned by Handlers into responses.
+	errorHandler ErrorHandler
+
+	// Logger used by the Mux.
+	// If this is nil, slog.Default() is used.
+	logger *slog.Logger
+
+	// Stops the Mux from printing the logo, and logging routes and requests.
+	quiet bool
+
+	// Proxies that are trusted to give the client of a request.
+	trustedProxies []netip.Prefix
+
+	// Maximum bytes of a multipart form kept in memory.
+	maxMultipartMemory int64
+
+	// Maximum size of each file of a multipart form, zero for no limit.
+	maxFileSize int64
+
+	// Maximum size of the body of a request, zero for no limit.
+	maxBodySize int64
+
+	// Validates objects bound by the Ctx.
+	// If this is nil, binding.Validator is used.
+	validator binding.StructValidator
+
+	// Renderers registered with the Mux, used by Ctx.Negotiate before the default renderers.
+	renderers []renderer
+
+	// Handler used when no route matches the request.
+	// Runs through all middleware of the Mux.
+	notFound Handler
+
+	// Handler used when a route matches the path of the request, but not the method.
+	// Runs through all middleware of the Mux.
+	methodNotAllowed Handler
+
+	// The notFound and methodNotAllowed Handlers with the middleware of the Mux.
+	// Built when they or the middleware are registered, rather than for each request.
+	notFoundFunc         http.HandlerFunc
+	methodNotAllowedFunc http.HandlerFunc
+
+	// Builds the default notFound and methodNotAllowed Handlers on the first request, if they have not been.
+	unmatchedOnce *sync.Once
+
+	// Pool of Ctx, reused across requests to prevent allocating a new Ctx for each.
+	pool *sync.Pool
+
 	// Slice of Handlers used as middleware for all Handlers.
 	// Will only apply to Handlers used after the x.Use(...) statement.
 	middleware []Handler
@@ -110,15 +279,140 @@ func New(args ...Config) Mux {
 		c = args[0]
 	}
 
+	if c.ErrorHandler == nil {
+		c.ErrorHandler = DefaultErrorHandler
+	}
+
+	if c.MaxMultipartMemory <= 0 {
+		c.MaxMultipartMemory = binding.DefaultMaxMultipartMemory
+	}
+
+	trustedProxies, err := parseTrustedProxies(c.TrustedProxies)
+	if err != nil {
+		logger := c.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+
+		logger.Error(err.Error())
+	}
+
 	return Mux{
-		mux:            http.NewServeMux(),
-		port:           c.Port,
-		host:           c.Host,
-		crt:            c.CRT,
-		key:            c.Key,
-		defaultOptions: c.DefaultOptions,
-		middleware:     make([]Handler, 0),
+		mux: http.NewServeMux(),
+		server: &http.Server{
+			ReadTimeout:       c.ReadTimeout,
+			ReadHeaderTimeout: c.ReadHeaderTimeout,
+			WriteTimeout:      c.WriteTimeout,
+			IdleTimeout:       c.IdleTimeout,
+			MaxHeaderBytes:    c.MaxHeaderBytes,
+		},
+		shutdownTimeout:    c.ShutdownTimeout,
+		port:               c.Port,
+		host:               c.Host,
+		crt:                c.CRT,
+		key:                c.Key,
+		defaultOptions:     c.DefaultOptions,
+		errorHandler:       c.ErrorHandler,
+		logger:             c.Logger,
+		quiet:              c.Quiet,
+		trustedProxies:     trustedProxies,
+		maxMultipartMemory: c.MaxMultipartMemory,
+		maxFileSize:        c.MaxFileSize,
+		maxBodySize:        c.MaxBodySize,
+		validator:          c.Validator,
+		notFound: func(ctx *Ctx) error {
+			return amperr.NotFound("")
+		},
+		methodNotAllowed: func(ctx *Ctx) error {
+			return amperr.MethodNotAllowed("")
+		},
+		pool: &sync.Pool{
+			New: func() any {
+				return newCtx(nil, nil)
+			},
+		},
+		unmatchedOnce: &sync.Once{},
+		middleware:    make([]Handler, 0),
+	}
+}
+
+// Get the logger of the Mux.
+// If the Mux was not given a Logger, slog.Default() is returned.
+func (m *Mux) Logger() *slog.Logger {
+	if m.logger == nil {
+		return slog.Default()
+	}
+
+	return m.logger
+}
+
+// Logs a route that has been added to the Mux, unless the Mux is quiet.
+func (m *Mux) logRoute(method string, path string) {
+	if m.quiet {
+		return
+	}
+
+	m.Logger().Info("route", "method", method, "path", path)
+}
+
+// Prints the logo and address of the Mux, unless the Mux is quiet.
+func (m *Mux) logServe() {
+	if m.quiet {
+		return
+	}
+
+	fmt.Print(amp + "\n")
+	m.Logger().Info("amp is running", "addr", m.server.Addr)
+}
+
+// Register a Renderer for a content type, used by Ctx.Negotiate.
+// Replaces any Renderer of the Mux for the same media type, including the defaults.
+// The content type is given to responses rendered by the Renderer.
+//
+//	err := a.Renderer("text/csv; charset=utf-8", func(obj any) ([]byte, error) {
+//		// encode your obj here
+//	})
+//
+// Returns an error if the content type is not valid.
+func (m *Mux) Renderer(contentType string, render Renderer) error {
+	r, err := newRenderer(contentType, render)
+	if err != nil {
+		return err
 	}
+
+	for i := range m.renderers {
+		if m.renderers[i].mediaType == r.mediaType {
+			m.renderers[i] = r
+			return nil
+		}
+	}
+
+	m.renderers = append(m.renderers, r)
+	return nil
+}
+
+// Get all of the renderers of the Mux, in order of preference.
+// Registered renderers come first, followed by any defaults they do not replace.
+func (m *Mux) allRenderers() []renderer {
+	renderers := append([]renderer{}, m.renderers...)
+	for _, r := range defaultRenderers {
+		if _, ok := findRenderer(m.renderers, r.mediaType); !ok {
+			renderers = append(renderers, r)
+		}
+	}
+
+	return renderers
+}
+
+// Prepares the owned server of the Mux before serving.
+// Uses the Mux itself as the handler, and the host and port as the address.
+func (m *Mux) prepare() {
+	if m.defaultOptions {
+		m.addOptions()
+	}
+
+	m.server.Addr = fmt.Sprintf("%s:%d", m.host, m.port)
+	m.server.Handler = m
 }
 
 // Adds the default options for the Mux.
@@ -132,45 +426,71 @@ func (m *Mux) addOptions() {
 
 // Makes a standard net/http HandlerFunc from a handler and middleware,
 // this is used when adding a given method to the Mux.
-// Makes a Ctx within that contains the handlers from both the current middleware and given middleware.
-// Handles any errors that occur within each handler and any aborts that occur in handlers.
+// The chain of handlers is built once, from both the current middleware and given middleware.
+// Each request uses a Ctx from the pool of the Mux, that contains the chain of handlers.
+// Handles any errors that occur within each handler, using the ErrorHandler of the Mux,
+// and any aborts that occur in handlers.
 // SLOGS info about the Handler also.
 func (m *Mux) Make(handler Handler, middleware ...Handler) http.HandlerFunc {
+	// constructs the func slice for the ctx, the is iterated on.
+	handlers := make([]Handler, 0, len(m.middleware)+len(middleware)+1)
+	handlers = append(handlers, m.middleware...)
+	handlers = append(handlers, middleware...)
+	handlers = append(handlers, handler)
+
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := newCtx(w, r)
-
-		// constructs the func slice for the ctx, the is iterated on.
-		ctx.handlers = append(ctx.handlers, m.middleware...)
-		ctx.handlers = append(ctx.handlers, middleware...)
-		ctx.handlers = append(ctx.handlers, handler)
-
-		// using iteration is preferred here.
-		// can still use Next for recursive Handler calling, less optimal.
-		for i, h := range ctx.handlers {
-			// we can skip within our handlers, if the index does not match, lets keep iterating.
-			ctx.index++
-			if ctx.index != i {
-				continue
-			}
-
-			// checks if the handler has an error, slogs it if it does.
-			err := h(ctx)
-			if err != nil {
-				slog.Error(err.Error())
-				return
-			}
-
-			// checks to see if the ctx was aborted.
-			// will exit the rest of the handlers if aborted.
-			// aborted ctx can be continued with the ctx.Next()
-			if ctx.aborted {
-				slog.Info(fmt.Sprintf("%s ABORTED %s %d", ctx.Method(), ctx.Path(), ctx.status))
-				return
-			}
+		// marks the request as matched when served by the Mux, and uses the writer it was given.
+		if response, ok := w.(*routeWriter); ok {
+			response.matched = true
+			w = response.ResponseWriter
 		}
 
-		slog.Info(fmt.Sprintf("%s %s %d", ctx.Method(), ctx.Path(), ctx.status))
+		ctx := m.pool.Get().(*Ctx)
+		ctx.reset(w, r)
+		ctx.mux = m
+		ctx.handlers = handlers
+		if m.maxBodySize > 0 {
+			ctx.LimitBody(m.maxBodySize)
+		}
+
+		m.handle(ctx)
+
+		// writes the status and headers if nothing else has, once all handlers have finished.
+		ctx.response.writeHeaderNow()
+		ctx.removeMultipartForm()
+
+		// drop references to the request, before the ctx is reused.
+		ctx.reset(nil, nil)
+		m.pool.Put(ctx)
+	}
+}
+
+// Runs the handlers of the ctx, each handler can also use Next to wrap the rest of the chain.
+// Handles any errors returned and any aborts, logs info about the request also.
+func (m *Mux) handle(ctx *Ctx) {
+	// checks if the handlers have an error, responds using the error handler.
+	err := ctx.Next()
+	if err != nil {
+		ctx.HandleError(err)
+	}
+
+	if m.quiet {
+		return
+	}
+
+	attrs := []any{
+		"method", ctx.Method(),
+		"path", ctx.Path(),
+		"status", ctx.GetStatus(),
+	}
+
+	// the rest of the handlers are not ran if aborted.
+	// aborted ctx can be continued with the ctx.Next()
+	if ctx.aborted {
+		attrs = append(attrs, "aborted", true)
 	}
+
+	m.Logger().Info("request", append(attrs, ctx.logAttrs()...)...)
 }
 
 // Add middleware to the Mux.
@@ -207,6 +527,110 @@ func (m *Mux) Use(middleware ...Handler) {
 	}
 
 	m.middleware = append(m.middleware, middleware...)
+
+	// the NotFound and MethodNotAllowed Handlers run through all middleware of the Mux.
+	m.notFoundFunc = m.Make(m.notFound)
+	m.methodNotAllowedFunc = m.Make(m.methodNotAllowed)
+}
+
+// Set the Handler used when no route matches the request.
+// The Handler runs through all middleware of the Mux, unlike the net/http default.
+//
+//	a.NotFound(func(ctx *amp.Ctx) error {
+//		return ctx.Render(status.NotFound, "nothing to see here")
+//	})
+//
+// By default an error.NotFound is returned, which is handled by the ErrorHandler.
+func (m *Mux) NotFound(handler Handler) {
+	m.notFound = handler
+	m.notFoundFunc = m.Make(handler)
+}
+
+// Set the Handler used when a route matches the path of the request, but not the method.
+// The Handler runs through all middleware of the Mux, unlike the net/http default.
+// The Allow header is set to the methods registered for the path before the Handler is ran.
+//
+//	a.MethodNotAllowed(func(ctx *amp.Ctx) error {
+//		return ctx.Render(status.MethodNotAllowed, "try another method")
+//	})
+//
+// By default an error.MethodNotAllowed is returned, which is handled by the ErrorHandler.
+func (m *Mux) MethodNotAllowed(handler Handler) {
+	m.methodNotAllowed = handler
+	m.methodNotAllowedFunc = m.Make(handler)
+}
+
+// Writer given to the ServeMux by ServeHTTP, used to find the requests that match no route.
+// Routes of the Mux mark the writer as matched, and write to the writer it wraps.
+// Responses of the ServeMux itself, such as a not found or a redirect, are kept rather than written.
+type routeWriter struct {
+	http.ResponseWriter
+
+	// Whether a route of the Mux matched the request.
+	matched bool
+
+	// The response of the ServeMux, if no route matched.
+	header http.Header
+	status int
+	body   []byte
+}
+
+// Get the headers of the response of the ServeMux.
+func (w *routeWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = make(http.Header)
+	}
+
+	return w.header
+}
+
+// Keep the status of the response of the ServeMux.
+func (w *routeWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Keep the body of the response of the ServeMux.
+func (w *routeWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	w.body = append(w.body, b...)
+	return len(b), nil
+}
+
+// Serves requests that match no route, once the ServeMux has responded to them.
+// Requests are given to the NotFound or MethodNotAllowed Handlers,
+// any other response of the ServeMux, such as a redirect, is written as it is.
+func (m *Mux) serveUnmatched(writer http.ResponseWriter, request *http.Request, response *routeWriter) {
+	switch response.status {
+	case http.StatusNotFound:
+		m.notFoundFunc(writer, request)
+	case http.StatusMethodNotAllowed:
+		writer.Header().Set("Allow", strings.Join(m.allowed(request), ", "))
+		m.methodNotAllowedFunc(writer, request)
+	default:
+		for key, values := range response.header {
+			writer.Header()[key] = values
+		}
+
+		writer.WriteHeader(response.status)
+		_, _ = writer.Write(response.body)
+	}
+}
+
+// Gets the methods that have a route registered for the path of the request.
+func (m *Mux) allowed(request *http.Request) []string {
+	allowed := make([]string, 0)
+
+	r := *request
+	for _, method := range methods {
+		r.Method = method
+		if _, pattern := m.mux.Handler(&r); pattern != "" {
+			allowed = append(allowed, method)
+		}
+	}
+
+	return allowed
 }
 
 // Generic handler, this can be used for a variety of http methods unlike specified ones, like Get.
@@ -224,7 +648,7 @@ func (m *Mux) Use(middleware ...Handler) {
 //
 // Generally recommended to use a specified method.
 func (m *Mux) Handler(path string, handler Handler, middleware ...Handler) {
-	slog.Info("HANDLER " + path)
+	m.logRoute("HANDLER", path)
 	m.mux.HandleFunc(path, m.Make(handler, middleware...))
 }
 
@@ -232,7 +656,7 @@ func (m *Mux) Handler(path string, handler Handler, middleware ...Handler) {
 // All given middleware will only be applied to this route.
 // Get requests should be used to retrieve data.
 func (m *Mux) Get(path string, handler Handler, middleware ...Handler) {
-	slog.Info("GET " + path)
+	m.logRoute("GET", path)
 	m.mux.HandleFunc(fmt.Sprintf("GET %s", path), m.Make(handler, middleware...))
 }
 
@@ -240,7 +664,7 @@ func (m *Mux) Get(path string, handler Handler, middleware ...Handler) {
 // All given middleware will only be applied to this route.
 // Post methods should be used for posting data or changing state.
 func (m *Mux) Post(path string, handler Handler, middleware ...Handler) {
-	slog.Info("POST " + path)
+	m.logRoute("POST", path)
 	m.mux.HandleFunc(fmt.Sprintf("POST %s", path), m.Make(handler, middleware...))
 }
 
@@ -248,7 +672,7 @@ func (m *Mux) Post(path string, handler Handler, middleware ...Handler) {
 // All given middleware will only be applied to this route.
 // Put methods should be used for posting data, changing state or updating state.
 func (m *Mux) Put(path string, handler Handler, middleware ...Handler) {
-	slog.Info("PUT " + path)
+	m.logRoute("PUT", path)
 	m.mux.HandleFunc(fmt.Sprintf("PUT %s", path), m.Make(handler, middleware...))
 }
 
@@ -256,7 +680,7 @@ func (m *Mux) Put(path string, handler Handler, middleware ...Handler) {
 // All given middleware will only be applied to this route.
 // Patch methods should be used for changing state or updating data.
 func (m *Mux) Patch(path string, handler Handler, middleware ...Handler) {
-	slog.Info("PATCH " + path)
+	m.logRoute("PATCH", path)
 	m.mux.HandleFunc(fmt.Sprintf("PATCH %s", path), m.Make(handler, middleware...))
 }
 
@@ -264,35 +688,35 @@ func (m *Mux) Patch(path string, handler Handler, middleware ...Handler) {
 // All given middleware will only be applied to this route.
 // Delete methods should be used for deleting data or a piece of state.
 func (m *Mux) Delete(path string, handler Handler, middleware ...Handler) {
-	slog.Info("DELETE " + path)
+	m.logRoute("DELETE", path)
 	m.mux.HandleFunc(fmt.Sprintf("DELETE %s", path), m.Make(handler, middleware...))
 }
 
 // Create a Head route with a given path, handler and optional middleware.
 // All given middleware will only be applied to this route.
 func (m *Mux) Head(path string, handler Handler, middleware ...Handler) {
-	slog.Info("HEAD " + path)
+	m.logRoute("HEAD", path)
 	m.mux.HandleFunc(fmt.Sprintf("HEAD %s", path), m.Make(handler, middleware...))
 }
 
 // Create an Options route with a given path, handler and optional middleware.
 // All given middleware will only be applied to this route.
 func (m *Mux) Options(path string, handler Handler, middleware ...Handler) {
-	slog.Info("OPTIONS " + path)
+	m.logRoute("OPTIONS", path)
 	m.mux.HandleFunc(fmt.Sprintf("OPTIONS %s", path), m.Make(handler, middleware...))
 }
 
 // Create a Connect route with a given path, handler and optional middleware.
 // All given middleware will only be applied to this route.
 func (m *Mux) Connect(path string, handler Handler, middleware ...Handler) {
-	slog.Info("CONNECT " + path)
+	m.logRoute("CONNECT", path)
 	m.mux.HandleFunc(fmt.Sprintf("CONNECT %s", path), m.Make(handler, middleware...))
 }
 
 // Create a Trace route with a given path, handler and optional middleware.
 // All given middleware will only be applied to this route.
 func (m *Mux) Trace(path string, handler Handler, middleware ...Handler) {
-	slog.Info("TRACE " + path)
+	m.logRoute("TRACE", path)
 	m.mux.HandleFunc(fmt.Sprintf("TRACE %s", path), m.Make(handler, middleware...))
 }
 
@@ -312,7 +736,6 @@ func (m *Mux) Trace(path string, handler Handler, middleware ...Handler) {
 //
 // Can now use the route /group/hello
 func (m *Mux) Group(group group) {
-	slog.Info("GROUP " + group.prefix)
 	for _, handler := range group.handlers {
 		middleware := append([]Handler{}, group.middleware...)
 		middleware = append(middleware, handler.middleware...)
@@ -340,7 +763,7 @@ func (m *Mux) Group(group group) {
 		case "TRACE":
 			m.Trace(path, handler.handler, middleware...)
 		default:
-			slog.Error("method not recognised, failed to add route",
+			m.Logger().Error("method not recognised, failed to add route",
 				"method", handler.method,
 				"path", path,
 			)
@@ -367,8 +790,24 @@ func (m *Mux) Group(group group) {
 //	}
 //
 // More commonly used when testing routes.
+// Requests that match no route are given to the NotFound or MethodNotAllowed Handlers.
 func (m *Mux) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
-	m.mux.ServeHTTP(writer, request)
+	m.unmatchedOnce.Do(func() {
+		if m.notFoundFunc == nil {
+			m.notFoundFunc = m.Make(m.notFound)
+		}
+
+		if m.methodNotAllowedFunc == nil {
+			m.methodNotAllowedFunc = m.Make(m.methodNotAllowed)
+		}
+	})
+
+	// the request is matched once, by the ServeMux, the routes of the Mux mark the writer if one matches.
+	response := &routeWriter{ResponseWriter: writer}
+	m.mux.ServeHTTP(response, request)
+	if !response.matched {
+		m.serveUnmatched(writer, request, response)
+	}
 }
 
 // Serve your Mux one all routes have and middleware have been added.
@@ -387,15 +826,13 @@ func (m *Mux) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 // If configured to add options, will add a catch all options method for pre-flight,
 // which is mostly used for cors features.
 // Can be disabled with New() and a custom configuration.
+//
+// Once Shutdown has been called, this will return http.ErrServerClosed.
 func (m *Mux) ListenAndServe() error {
-	if m.defaultOptions {
-		m.addOptions()
-	}
-
-	fmt.Print(amp + "\n")
-	slog.Info(fmt.Sprintf("amp is running on %s:%d", m.host, m.port))
+	m.prepare()
+	m.logServe()
 
-	return http.ListenAndServe(fmt.Sprintf("%s:%d", m.host, m.port), m.mux)
+	return m.server.ListenAndServe()
 }
 
 // Serve your Mux one all routes have and middleware have been added.
@@ -423,16 +860,96 @@ func (m *Mux) ListenAndServe() error {
 // If configured to add options, will add a catch all options method for pre-flight,
 // which is mostly used for cors features.
 // Can be disabled with New() and a custom configuration.
+//
+// Once Shutdown has been called, this will return http.ErrServerClosed.
 func (m *Mux) ListenAndServeTLS() error {
-	if m.defaultOptions {
-		m.addOptions()
-	}
-
 	if m.crt == "" || m.key == "" {
 		return errors.New("error, no crt or key given")
 	}
 
-	fmt.Print(amp + "\n")
+	m.prepare()
+	m.logServe()
+
+	return m.server.ListenAndServeTLS(m.crt, m.key)
+}
+
+// Gracefully shuts down the Mux without interrupting any active connections.
+// Stops accepting new connections, then waits for in-flight requests to finish,
+// or for the given context to be done, whichever comes first.
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//
+//	err := a.Shutdown(ctx)
+//
+// Once called, ListenAndServe and ListenAndServeTLS will return http.ErrServerClosed.
+func (m *Mux) Shutdown(ctx context.Context) error {
+	return m.server.Shutdown(ctx)
+}
+
+// Register a function to run when the Mux is shutdown, such as to stop a goroutine started by middleware.
+// Each function is ran in its own goroutine once Shutdown is called, Shutdown does not wait for them to return.
+//
+//	a.OnShutdown(func() {
+//		db.Close()
+//	})
+func (m *Mux) OnShutdown(fn func()) {
+	m.server.RegisterOnShutdown(fn)
+}
+
+// Serve your Mux and shut it down gracefully when an interrupt or terminate signal is received.
+// Uses ListenAndServeTLS if the configuration has a CRT and Key, otherwise ListenAndServe.
+// This will run until a signal is received, or the Mux fails to serve.
+//
+//	func main() {
+//		a := amp.New()
+//
+//		a.Get("/path", func(ctx *amp.Ctx) {
+//			return ctx.Render(status.OK, "hello")
+//		})
+//
+//		log.Fatalln(a.Run())
+//	}
+//
+// In-flight requests are given the ShutdownTimeout of the configuration to finish.
+// Returns nil once the Mux has been shutdown cleanly.
+func (m *Mux) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		if m.crt != "" && m.key != "" {
+			errs <- m.ListenAndServeTLS()
+			return
+		}
+
+		errs <- m.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		// the Mux has already been shutdown elsewhere.
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+
+		return err
+	case <-ctx.Done():
+	}
+
+	// restore default signal behaviour, a second signal will now kill the program.
+	stop()
+	if !m.quiet {
+		m.Logger().Info("amp is shutting down")
+	}
+
+	shutdownCtx := context.Background()
+	if m.shutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		shutdownCtx, cancel = context.WithTimeout(shutdownCtx, m.shutdownTimeout)
+		defer cancel()
+	}
 
-	return http.ListenAndServeTLS(fmt.Sprintf("%s:%d", m.host, m.port), m.crt, m.key, m.mux)
+	return m.Shutdown(shutdownCtx)
 }
@@ -0,0 +1,122 @@
+package amp
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joseph-beck/amp/pkg/status"
+)
+
+// Silences the default logger for the duration of a benchmark.
+func quietLogs(b *testing.B) {
+	logger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	b.Cleanup(func() {
+		slog.SetDefault(logger)
+	})
+}
+
+// Serves the given request b.N times.
+func benchmarkRequest(b *testing.B, amp *Mux, request *http.Request) {
+	quietLogs(b)
+
+	writer := &discardWriter{header: make(http.Header)}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		amp.ServeHTTP(writer, request)
+	}
+}
+
+func BenchmarkMuxStatic(b *testing.B) {
+	amp := New()
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		ctx.Status(status.OK)
+		return nil
+	})
+
+	benchmarkRequest(b, &amp, httptest.NewRequest("GET", "/test", nil))
+}
+
+func BenchmarkMuxParam(b *testing.B) {
+	amp := New()
+
+	amp.Get("/test/{key}", func(ctx *Ctx) error {
+		_, err := ctx.Param("key")
+		return err
+	})
+
+	benchmarkRequest(b, &amp, httptest.NewRequest("GET", "/test/value", nil))
+}
+
+func BenchmarkMuxMiddleware(b *testing.B) {
+	amp := New()
+
+	for i := 0; i < 5; i++ {
+		amp.Use(func(ctx *Ctx) error {
+			return nil
+		})
+	}
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		ctx.Status(status.OK)
+		return nil
+	}, func(ctx *Ctx) error {
+		return ctx.Next()
+	})
+
+	benchmarkRequest(b, &amp, httptest.NewRequest("GET", "/test", nil))
+}
+
+func BenchmarkMuxValues(b *testing.B) {
+	amp := New()
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		ctx.Set("key", "value")
+		_, err := ctx.Get("key")
+		return err
+	})
+
+	benchmarkRequest(b, &amp, httptest.NewRequest("GET", "/test", nil))
+}
+
+func BenchmarkMuxRenderJSON(b *testing.B) {
+	amp := New()
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		return ctx.RenderJSON(status.OK, Mock{Key: "value"})
+	})
+
+	benchmarkRequest(b, &amp, httptest.NewRequest("GET", "/test", nil))
+}
+
+func BenchmarkMuxParallel(b *testing.B) {
+	quietLogs(b)
+
+	amp := New()
+
+	amp.Get("/test/{key}", func(ctx *Ctx) error {
+		ctx.Set("key", "value")
+		return nil
+	}, func(ctx *Ctx) error {
+		return ctx.Next()
+	})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		request := httptest.NewRequest("GET", "/test/value", nil)
+		writer := &discardWriter{header: make(http.Header)}
+
+		for pb.Next() {
+			amp.ServeHTTP(writer, request)
+		}
+	})
+}
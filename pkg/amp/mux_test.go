@@ -1 +1,500 @@
 package amp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joseph-beck/amp/pkg/binding"
+	amperr "github.com/joseph-beck/amp/pkg/error"
+	"github.com/joseph-beck/amp/pkg/status"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewServerConfig(t *testing.T) {
+	amp := New(Config{
+		Port:              8080,
+		ReadTimeout:       1 * time.Second,
+		ReadHeaderTimeout: 2 * time.Second,
+		WriteTimeout:      3 * time.Second,
+		IdleTimeout:       4 * time.Second,
+		MaxHeaderBytes:    1024,
+		ShutdownTimeout:   5 * time.Second,
+	})
+
+	assert.Equal(t, 1*time.Second, amp.server.ReadTimeout)
+	assert.Equal(t, 2*time.Second, amp.server.ReadHeaderTimeout)
+	assert.Equal(t, 3*time.Second, amp.server.WriteTimeout)
+	assert.Equal(t, 4*time.Second, amp.server.IdleTimeout)
+	assert.Equal(t, 1024, amp.server.MaxHeaderBytes)
+	assert.Equal(t, 5*time.Second, amp.shutdownTimeout)
+}
+
+func TestMuxShutdown(t *testing.T) {
+	amp := New(Config{
+		Port:           0,
+		Host:           "127.0.0.1",
+		DefaultOptions: false,
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- amp.ListenAndServe()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	err := amp.Shutdown(ctx)
+	assert.NoError(t, err)
+
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, http.ErrServerClosed)
+	case <-time.After(1 * time.Second):
+		t.Fatal("ListenAndServe did not return after Shutdown")
+	}
+}
+
+func TestMuxRunShutdown(t *testing.T) {
+	amp := New(Config{
+		Port:            0,
+		Host:            "127.0.0.1",
+		DefaultOptions:  false,
+		ShutdownTimeout: 1 * time.Second,
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- amp.Run()
+	}()
+
+	err := amp.Shutdown(context.Background())
+	assert.NoError(t, err)
+
+	select {
+	case err := <-errs:
+		assert.NoError(t, err)
+	case <-time.After(1 * time.Second):
+		t.Fatal("Run did not return after Shutdown")
+	}
+}
+
+func TestMuxOnShutdown(t *testing.T) {
+	amp := New()
+
+	called := make(chan struct{}, 2)
+	amp.OnShutdown(func() {
+		called <- struct{}{}
+	})
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		ctx.OnShutdown(func() {
+			called <- struct{}{}
+		})
+		return nil
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+
+	err := amp.Shutdown(context.Background())
+	assert.NoError(t, err)
+
+	for range 2 {
+		select {
+		case <-called:
+		case <-time.After(1 * time.Second):
+			t.Fatal("OnShutdown was not called after Shutdown")
+		}
+	}
+}
+
+func TestMuxNotFound(t *testing.T) {
+	amp := New()
+
+	amp.Use(func(ctx *Ctx) error {
+		ctx.Header("X-Middleware", "true")
+		return nil
+	})
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		return nil
+	})
+
+	request := httptest.NewRequest("GET", "/missing", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.NotFound, writer.Code)
+	assert.Equal(t, "true", writer.Header().Get("X-Middleware"))
+	assert.Equal(t, problemJSONContentType[0], writer.Header().Get("Content-Type"))
+
+	amp.NotFound(func(ctx *Ctx) error {
+		return ctx.Render(status.NotFound, "custom")
+	})
+
+	request = httptest.NewRequest("GET", "/missing", nil)
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.NotFound, writer.Code)
+	assert.Equal(t, "true", writer.Header().Get("X-Middleware"))
+	assert.Equal(t, "custom", writer.Body.String())
+
+	// unclean paths are still redirected by the ServeMux.
+	request = httptest.NewRequest("GET", "/missing/../test", nil)
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, http.StatusTemporaryRedirect, writer.Code)
+
+	amp.Get("/dir/", func(ctx *Ctx) error {
+		return nil
+	})
+
+	request = httptest.NewRequest("GET", "/dir", nil)
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, http.StatusTemporaryRedirect, writer.Code)
+	assert.Equal(t, "/dir/", writer.Header().Get("Location"))
+}
+
+func TestMuxRootHandler(t *testing.T) {
+	amp := New()
+
+	amp.Get("/test/{id}", func(ctx *Ctx) error {
+		id, err := ctx.Param("id")
+		if err != nil {
+			return err
+		}
+
+		return ctx.Render(status.OK, id)
+	})
+
+	// a Handler for "/" is given every request that matches no other route.
+	amp.Handler("/", func(ctx *Ctx) error {
+		return ctx.Render(status.OK, "root")
+	})
+
+	request := httptest.NewRequest("GET", "/test/1", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.OK, writer.Code)
+	assert.Equal(t, "1", writer.Body.String())
+
+	request = httptest.NewRequest("POST", "/missing", nil)
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.OK, writer.Code)
+	assert.Equal(t, "root", writer.Body.String())
+}
+
+func TestMuxCatchAllHandler(t *testing.T) {
+	amp := New()
+
+	amp.Handler("/{path...}", func(ctx *Ctx) error {
+		path, err := ctx.Param("path")
+		if err != nil {
+			return err
+		}
+
+		return ctx.Render(status.OK, path)
+	})
+
+	request := httptest.NewRequest("GET", "/any/path", nil)
+	writer := httptest.NewRecorder()
+	assert.NotPanics(t, func() { amp.ServeHTTP(writer, request) })
+	assert.Equal(t, status.OK, writer.Code)
+	assert.Equal(t, "any/path", writer.Body.String())
+
+	// routes registered after the first request still match, with requests for other methods not allowed.
+	amp = New()
+	amp.Get("/{path...}", func(ctx *Ctx) error {
+		return nil
+	})
+
+	request = httptest.NewRequest("GET", "/any", nil)
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.OK, writer.Code)
+
+	amp.Post("/any/{id}", func(ctx *Ctx) error {
+		return nil
+	})
+
+	request = httptest.NewRequest("DELETE", "/any/1", nil)
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.MethodNotAllowed, writer.Code)
+	assert.Equal(t, "GET, HEAD, POST", writer.Header().Get("Allow"))
+}
+
+func TestMuxMethodNotAllowed(t *testing.T) {
+	amp := New()
+
+	amp.Use(func(ctx *Ctx) error {
+		ctx.Header("X-Middleware", "true")
+		return nil
+	})
+
+	amp.Get("/test/{id}", func(ctx *Ctx) error {
+		return nil
+	})
+
+	amp.Delete("/test/{id}", func(ctx *Ctx) error {
+		return nil
+	})
+
+	request := httptest.NewRequest("POST", "/test/1", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.MethodNotAllowed, writer.Code)
+	assert.Equal(t, "GET, HEAD, DELETE", writer.Header().Get("Allow"))
+	assert.Equal(t, "true", writer.Header().Get("X-Middleware"))
+
+	amp.MethodNotAllowed(func(ctx *Ctx) error {
+		return ctx.Render(status.MethodNotAllowed, "custom")
+	})
+
+	request = httptest.NewRequest("PUT", "/test/1", nil)
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.MethodNotAllowed, writer.Code)
+	assert.Equal(t, "GET, HEAD, DELETE", writer.Header().Get("Allow"))
+	assert.Equal(t, "custom", writer.Body.String())
+
+	request = httptest.NewRequest("GET", "/test/1", nil)
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, status.OK, writer.Code)
+}
+
+func TestMuxUseOrder(t *testing.T) {
+	amp := New()
+
+	amp.Get("/test/one", func(ctx *Ctx) error {
+		return nil
+	})
+
+	amp.Use(func(ctx *Ctx) error {
+		ctx.Header("X-Middleware", "true")
+		return nil
+	})
+
+	amp.Get("/test/two", func(ctx *Ctx) error {
+		return nil
+	})
+
+	request := httptest.NewRequest("GET", "/test/one", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, "", writer.Header().Get("X-Middleware"))
+
+	request = httptest.NewRequest("GET", "/test/two", nil)
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, "true", writer.Header().Get("X-Middleware"))
+}
+
+func TestMuxPool(t *testing.T) {
+	amp := New()
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		_, err := ctx.Get("key")
+		assert.Error(t, err)
+
+		ctx.Set("key", "value")
+		return nil
+	})
+
+	for i := 0; i < 3; i++ {
+		request := httptest.NewRequest("GET", "/test", nil)
+		writer := httptest.NewRecorder()
+		amp.ServeHTTP(writer, request)
+		assert.Equal(t, status.OK, writer.Code)
+	}
+}
+
+func TestMuxLogger(t *testing.T) {
+	logs := bytes.Buffer{}
+
+	amp := New(Config{
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		ctx.Logger().Info("handler")
+		return amperr.BadRequest("")
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Contains(t, logs.String(), "msg=route method=GET path=/test")
+	assert.Contains(t, logs.String(), "msg=handler")
+	assert.Contains(t, logs.String(), "level=WARN msg=\"Bad Request\" method=GET path=/test status=400")
+	assert.Contains(t, logs.String(), "msg=request method=GET path=/test status=400")
+
+	// errors of the server are logged as errors, those of the client as warnings.
+	amp.Get("/error", func(ctx *Ctx) error {
+		return errors.New("failed")
+	})
+
+	request = httptest.NewRequest("GET", "/error", nil)
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Contains(t, logs.String(), "level=ERROR msg=failed method=GET path=/error status=500")
+
+	logs.Reset()
+
+	amp = New(Config{
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+		Quiet:  true,
+	})
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		return nil
+	})
+
+	request = httptest.NewRequest("GET", "/test", nil)
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, "", logs.String())
+}
+
+func TestMuxRenderer(t *testing.T) {
+	amp := New()
+
+	err := amp.Renderer("text/plain; charset=utf-8", func(obj any) ([]byte, error) {
+		return []byte(fmt.Sprint(obj)), nil
+	})
+	assert.NoError(t, err)
+
+	err = amp.Renderer("application/json", func(obj any) ([]byte, error) {
+		return []byte("custom"), nil
+	})
+	assert.NoError(t, err)
+
+	err = amp.Renderer("invalid/", nil)
+	assert.Error(t, err)
+
+	amp.Get("/test", func(ctx *Ctx) error {
+		return ctx.Negotiate(status.OK, "hello")
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	request.Header.Set("Accept", "text/*")
+	writer := httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, "text/plain; charset=utf-8", writer.Header().Get("Content-Type"))
+	assert.Equal(t, "hello", writer.Body.String())
+
+	request = httptest.NewRequest("GET", "/test", nil)
+	request.Header.Set("Accept", "application/json")
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, "application/json", writer.Header().Get("Content-Type"))
+	assert.Equal(t, "custom", writer.Body.String())
+
+	// registered renderers are preferred over the defaults.
+	request = httptest.NewRequest("GET", "/test", nil)
+	writer = httptest.NewRecorder()
+	amp.ServeHTTP(writer, request)
+	assert.Equal(t, "hello", writer.Body.String())
+}
+
+func TestMuxValidator(t *testing.T) {
+	type request struct {
+		Value int `query:"value" binding:"even"`
+	}
+
+	even := binding.NewValidator()
+	err := even.RegisterRule("even", func(field binding.FieldLevel) bool {
+		return field.Field().Int()%2 == 0
+	})
+	assert.NoError(t, err)
+
+	odd := binding.NewValidator()
+	err = odd.RegisterRule("even", func(field binding.FieldLevel) bool {
+		return field.Field().Int()%2 == 1
+	})
+	assert.NoError(t, err)
+
+	handler := func(ctx *Ctx) error {
+		var obj request
+		if err := ctx.BindQuery(&obj); err != nil {
+			return err
+		}
+
+		return ctx.Render(status.OK, "valid")
+	}
+
+	cfg := Default()
+	cfg.Validator = even
+	evenMux := New(cfg)
+	evenMux.Get("/test", handler)
+
+	cfg.Validator = odd
+	oddMux := New(cfg)
+	oddMux.Get("/test", handler)
+
+	req := httptest.NewRequest("GET", "/test?value=2", nil)
+	writer := httptest.NewRecorder()
+	evenMux.ServeHTTP(writer, req)
+	assert.Equal(t, status.OK, writer.Code)
+
+	req = httptest.NewRequest("GET", "/test?value=2", nil)
+	writer = httptest.NewRecorder()
+	oddMux.ServeHTTP(writer, req)
+	assert.Equal(t, status.UnprocessableContent, writer.Code)
+
+	req = httptest.NewRequest("GET", "/test?value=3", nil)
+	writer = httptest.NewRecorder()
+	oddMux.ServeHTTP(writer, req)
+	assert.Equal(t, status.OK, writer.Code)
+}
+
+func TestMuxMalformedBody(t *testing.T) {
+	amp := New()
+
+	amp.Post("/test", func(ctx *Ctx) error {
+		var obj Mock
+		if err := ctx.Bind(&obj); err != nil {
+			return err
+		}
+
+		return ctx.Render(status.OK, obj.Key)
+	})
+
+	tests := []struct {
+		contentType string
+		body        string
+	}{
+		{"application/json", `{`},
+		{"application/xml", `<mock>`},
+		{"application/yaml", `key: [`},
+		{"application/toml", `key = `},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("POST", "/test", strings.NewReader(test.body))
+		req.Header.Set("Content-Type", test.contentType)
+		writer := httptest.NewRecorder()
+		amp.ServeHTTP(writer, req)
+		assert.Equal(t, status.BadRequest, writer.Code, test.contentType)
+		assert.Equal(t, problemJSONContentType[0], writer.Header().Get("Content-Type"), test.contentType)
+		assert.JSONEq(t, `{
+			"type": "about:blank",
+			"title": "Bad Request",
+			"status": 400,
+			"detail": "malformed request",
+			"instance": "/test"
+		}`, writer.Body.String(), test.contentType)
+	}
+}
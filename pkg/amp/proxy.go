@@ -0,0 +1,205 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Amp is a web framework made using the Go 1.22 Mux.
+// Please ensure you are using Go 1.22, minimum, when using Amp.
+package amp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// A hop of a request through a proxy, as described by the forwarding headers.
+type hop struct {
+	// Address the hop was forwarded for, invalid if unknown or obfuscated.
+	addr netip.Addr
+
+	// Scheme the hop was received with, empty if not given.
+	proto string
+
+	// Host the hop was received with, empty if not given.
+	host string
+}
+
+// Parses trusted proxies, given as CIDRs, "10.0.0.0/8", or addresses, "10.0.0.1".
+// Invalid proxies are skipped, an error is returned for each of them.
+func parseTrustedProxies(proxies []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	errs := make([]error, 0)
+	for _, proxy := range proxies {
+		if strings.Contains(proxy, "/") {
+			prefix, err := netip.ParsePrefix(proxy)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("error, invalid trusted proxy %q: %w", proxy, err))
+				continue
+			}
+
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error, invalid trusted proxy %q: %w", proxy, err))
+			continue
+		}
+
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	return prefixes, errors.Join(errs...)
+}
+
+// Checks if an address is within any of the trusted proxies.
+func isTrusted(prefixes []netip.Prefix, addr netip.Addr) bool {
+	if !addr.IsValid() {
+		return false
+	}
+
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Parses an address that may have a port, "192.0.2.1:80", or brackets, "[2001:db8::1]:80".
+// Quotes around the address are removed, as used by the Forwarded header.
+func parseAddr(s string) netip.Addr {
+	s = strings.Trim(strings.TrimSpace(s), "\"")
+
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}
+	}
+
+	return addr.Unmap()
+}
+
+// Splits the values of a header that is a comma separated list, across all of its lines.
+func headerList(header http.Header, key string) []string {
+	list := make([]string, 0)
+	for _, line := range header.Values(key) {
+		for _, val := range strings.Split(line, ",") {
+			if val = strings.TrimSpace(val); val != "" {
+				list = append(list, val)
+			}
+		}
+	}
+
+	return list
+}
+
+// Gets the hops of a request from the Forwarded header, RFC 7239.
+//
+//	Forwarded: for=192.0.2.60;proto=https;host=example.com, for="[2001:db8::17]:4711"
+func forwardedHops(header http.Header) []hop {
+	hops := make([]hop, 0)
+	for _, element := range headerList(header, "Forwarded") {
+		h := hop{}
+		for _, pair := range strings.Split(element, ";") {
+			key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok {
+				continue
+			}
+
+			val = strings.Trim(val, "\"")
+			switch strings.ToLower(key) {
+			case "for":
+				h.addr = parseAddr(val)
+			case "proto":
+				h.proto = strings.ToLower(val)
+			case "host":
+				h.host = val
+			}
+		}
+
+		hops = append(hops, h)
+	}
+
+	return hops
+}
+
+// Gets the hops of a request from the X-Forwarded-For header.
+// The X-Forwarded-Proto and X-Forwarded-Host headers are used for the last hop,
+// as each proxy replaces them rather than appending to them.
+func xForwardedHops(header http.Header) []hop {
+	hops := make([]hop, 0)
+	for _, val := range headerList(header, "X-Forwarded-For") {
+		hops = append(hops, hop{addr: parseAddr(val)})
+	}
+
+	if len(hops) == 0 {
+		return hops
+	}
+
+	if protos := headerList(header, "X-Forwarded-Proto"); len(protos) > 0 {
+		hops[len(hops)-1].proto = strings.ToLower(protos[len(protos)-1])
+	}
+
+	if hosts := headerList(header, "X-Forwarded-Host"); len(hosts) > 0 {
+		hops[len(hops)-1].host = hosts[len(hosts)-1]
+	}
+
+	return hops
+}
+
+// Resolves the hop of the client of a request, received from a given remote address.
+// The forwarding headers are only used if the remote address is a trusted proxy,
+// they are then walked from the closest proxy, until an address that is not trusted is found.
+// Uses the Forwarded header, then X-Forwarded-For, then X-Real-IP.
+func resolveClient(prefixes []netip.Prefix, remote netip.Addr, header http.Header) (hop, bool) {
+	if !isTrusted(prefixes, remote) {
+		return hop{}, false
+	}
+
+	hops := forwardedHops(header)
+	if len(hops) == 0 {
+		hops = xForwardedHops(header)
+	}
+
+	client, found := hop{}, false
+	for i := len(hops) - 1; i >= 0; i-- {
+		if !hops[i].addr.IsValid() {
+			break
+		}
+
+		// the scheme and host given by a proxy are kept as we walk past it.
+		if hops[i].proto == "" {
+			hops[i].proto = client.proto
+		}
+
+		if hops[i].host == "" {
+			hops[i].host = client.host
+		}
+
+		client, found = hops[i], true
+		if !isTrusted(prefixes, hops[i].addr) {
+			break
+		}
+	}
+
+	if found {
+		return client, true
+	}
+
+	if addr := parseAddr(header.Get("X-Real-IP")); addr.IsValid() {
+		return hop{addr: addr}, true
+	}
+
+	return hop{}, false
+}
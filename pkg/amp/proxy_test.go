@@ -0,0 +1,90 @@
+package amp
+
+import (
+	"net/http"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	prefixes, err := parseTrustedProxies([]string{"10.1.2.3/8", "192.168.1.1", "::1", "bad", "1.2.3.4/99"})
+	assert.Error(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.168.1.1/32"),
+		netip.MustParsePrefix("::1/128"),
+	}, prefixes)
+
+	prefixes, err = parseTrustedProxies(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, prefixes)
+}
+
+func TestParseAddr(t *testing.T) {
+	assert.Equal(t, netip.MustParseAddr("192.0.2.1"), parseAddr("192.0.2.1"))
+	assert.Equal(t, netip.MustParseAddr("192.0.2.1"), parseAddr("192.0.2.1:8080"))
+	assert.Equal(t, netip.MustParseAddr("2001:db8::1"), parseAddr("\"[2001:db8::1]:4711\""))
+	assert.Equal(t, netip.MustParseAddr("2001:db8::1"), parseAddr("[2001:db8::1]"))
+	assert.Equal(t, netip.MustParseAddr("192.0.2.1"), parseAddr("::ffff:192.0.2.1"))
+	assert.False(t, parseAddr("unknown").IsValid())
+	assert.False(t, parseAddr("_hidden").IsValid())
+}
+
+func TestForwardedHops(t *testing.T) {
+	header := http.Header{}
+	header.Add("Forwarded", "for=192.0.2.60;proto=HTTPS;host=example.com, for=\"[2001:db8::17]:4711\"")
+	header.Add("Forwarded", "for=unknown")
+
+	hops := forwardedHops(header)
+	assert.Equal(t, []hop{
+		{addr: netip.MustParseAddr("192.0.2.60"), proto: "https", host: "example.com"},
+		{addr: netip.MustParseAddr("2001:db8::17")},
+		{},
+	}, hops)
+}
+
+func TestResolveClient(t *testing.T) {
+	prefixes, _ := parseTrustedProxies([]string{"10.0.0.0/8"})
+	proxy := netip.MustParseAddr("10.0.0.1")
+
+	header := http.Header{}
+	header.Set("X-Forwarded-For", "1.1.1.1, 2.2.2.2, 10.0.0.2")
+	header.Set("X-Forwarded-Proto", "https")
+
+	// only the closest address that is not trusted is used, the rest could be spoofed.
+	client, ok := resolveClient(prefixes, proxy, header)
+	assert.True(t, ok)
+	assert.Equal(t, "2.2.2.2", client.addr.String())
+	assert.Equal(t, "https", client.proto)
+
+	_, ok = resolveClient(prefixes, netip.MustParseAddr("2.2.2.2"), header)
+	assert.False(t, ok)
+
+	header = http.Header{}
+	header.Set("X-Forwarded-For", "10.0.0.3, 10.0.0.2")
+
+	client, ok = resolveClient(prefixes, proxy, header)
+	assert.True(t, ok)
+	assert.Equal(t, "10.0.0.3", client.addr.String())
+
+	header = http.Header{}
+	header.Set("Forwarded", "for=3.3.3.3;proto=https;host=example.com")
+	header.Set("X-Forwarded-For", "4.4.4.4")
+
+	client, ok = resolveClient(prefixes, proxy, header)
+	assert.True(t, ok)
+	assert.Equal(t, "3.3.3.3", client.addr.String())
+	assert.Equal(t, "example.com", client.host)
+
+	header = http.Header{}
+	header.Set("X-Real-IP", "5.5.5.5")
+
+	client, ok = resolveClient(prefixes, proxy, header)
+	assert.True(t, ok)
+	assert.Equal(t, "5.5.5.5", client.addr.String())
+
+	_, ok = resolveClient(prefixes, proxy, http.Header{})
+	assert.False(t, ok)
+}
@@ -0,0 +1,179 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Amp is a web framework made using the Go 1.22 Mux.
+// Please ensure you are using Go 1.22, minimum, when using Amp.
+package amp
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Amp Renderer.
+// Encodes an obj into the body of a response, used by Ctx.Negotiate.
+type Renderer func(obj any) ([]byte, error)
+
+// A Renderer, with the media type it renders.
+type renderer struct {
+	// Media type without parameters, for example "application/json".
+	mediaType string
+
+	// Content type of the response, for example "application/json; charset=utf-8".
+	contentType []string
+
+	// Encodes the obj.
+	render Renderer
+}
+
+// Renderers used by every Mux, in order of preference.
+// Used when the Accept header allows any media type.
+var defaultRenderers = []renderer{
+	{mediaType: "application/json", contentType: jsonContentType, render: json.Marshal},
+	{mediaType: "application/xml", contentType: xmlContentType, render: xml.Marshal},
+	{mediaType: "application/x-yaml", contentType: yamlContentType, render: yaml.Marshal},
+	{mediaType: "application/yaml", contentType: []string{"application/yaml; charset=utf-8"}, render: yaml.Marshal},
+	{mediaType: "application/toml", contentType: tomlContentType, render: toml.Marshal},
+}
+
+// Creates a renderer for a given content type.
+// The media type is the content type without any parameters.
+func newRenderer(contentType string, render Renderer) (renderer, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return renderer{}, err
+	}
+
+	return renderer{
+		mediaType:   mediaType,
+		contentType: []string{contentType},
+		render:      render,
+	}, nil
+}
+
+// Finds the renderer for a media type in a list of renderers.
+func findRenderer(renderers []renderer, mediaType string) (renderer, bool) {
+	for _, r := range renderers {
+		if r.mediaType == mediaType {
+			return r, true
+		}
+	}
+
+	return renderer{}, false
+}
+
+// A media range of an Accept header, for example "text/*;q=0.8".
+type mediaRange struct {
+	// Type of the range, "*" for any.
+	typ string
+
+	// Subtype of the range, "*" for any.
+	subtype string
+
+	// Quality, or weight, of the range from 0 to 1.
+	q float64
+}
+
+// Parses the media ranges of an Accept header.
+// Invalid ranges are ignored, ranges without a quality have a quality of 1.
+func parseAccept(header string) []mediaRange {
+	ranges := make([]mediaRange, 0)
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok || (typ == "*" && subtype != "*") {
+			continue
+		}
+
+		q := 1.0
+		if val, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(val, 64)
+			if err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+
+		ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, q: q})
+	}
+
+	return ranges
+}
+
+// Gets the quality of a media type, from the most specific range that matches it.
+// Returns -1 if no range matches.
+func quality(ranges []mediaRange, mediaType string) float64 {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+
+	q, specificity := -1.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*":
+			s = 0
+		}
+
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+
+	return q
+}
+
+// Chooses the offer that is most preferred by an Accept header.
+// Earlier offers are preferred when the qualities are equal,
+// an empty header accepts the first offer.
+// Returns false if the header accepts none of the offers.
+func negotiate(header string, offers []string) (string, bool) {
+	if len(offers) == 0 {
+		return "", false
+	}
+
+	if strings.TrimSpace(header) == "" {
+		return offers[0], true
+	}
+
+	ranges := parseAccept(header)
+
+	type choice struct {
+		offer string
+		q     float64
+	}
+
+	choices := make([]choice, 0, len(offers))
+	for _, offer := range offers {
+		if q := quality(ranges, offer); q > 0 {
+			choices = append(choices, choice{offer: offer, q: q})
+		}
+	}
+
+	if len(choices) == 0 {
+		return "", false
+	}
+
+	sort.SliceStable(choices, func(i, j int) bool {
+		return choices[i].q > choices[j].q
+	})
+
+	return choices[0].offer, true
+}
@@ -0,0 +1,50 @@
+package amp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAccept(t *testing.T) {
+	ranges := parseAccept("text/html, application/json;q=0.9, */*;q=0.1, bad, */json, text/plain;q=2")
+	assert.Equal(t, []mediaRange{
+		{typ: "text", subtype: "html", q: 1},
+		{typ: "application", subtype: "json", q: 0.9},
+		{typ: "*", subtype: "*", q: 0.1},
+	}, ranges)
+}
+
+func TestQuality(t *testing.T) {
+	ranges := parseAccept("application/*;q=0.5, application/json;q=0.9, */*;q=0.1")
+	assert.Equal(t, 0.9, quality(ranges, "application/json"))
+	assert.Equal(t, 0.5, quality(ranges, "application/xml"))
+	assert.Equal(t, 0.1, quality(ranges, "text/plain"))
+	assert.Equal(t, -1.0, quality(parseAccept("text/html"), "application/json"))
+}
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{"application/json", "application/xml", "application/x-yaml"}
+
+	offer, ok := negotiate("", offers)
+	assert.True(t, ok)
+	assert.Equal(t, "application/json", offer)
+
+	offer, ok = negotiate("*/*", offers)
+	assert.True(t, ok)
+	assert.Equal(t, "application/json", offer)
+
+	offer, ok = negotiate("application/json;q=0.5, application/xml", offers)
+	assert.True(t, ok)
+	assert.Equal(t, "application/xml", offer)
+
+	offer, ok = negotiate("application/*;q=0.8, application/json;q=0", offers)
+	assert.True(t, ok)
+	assert.Equal(t, "application/xml", offer)
+
+	_, ok = negotiate("text/html", offers)
+	assert.False(t, ok)
+
+	_, ok = negotiate("*/*", nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,135 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Amp is a web framework made using the Go 1.22 Mux.
+// Please ensure you are using Go 1.22, minimum, when using Amp.
+package amp
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/joseph-beck/amp/pkg/status"
+)
+
+// Wraps the http.ResponseWriter of the Ctx.
+// The status is not written until the body is first written, or the request has finished,
+// so headers can still be set after the status has been.
+// Tracks the status, the number of bytes written and whether the headers have been written.
+type responseWriter struct {
+	http.ResponseWriter
+
+	// Status that will be, or has been, written.
+	// This is status.OK by default.
+	status int
+
+	// Number of bytes of the body that have been written.
+	size int
+
+	// Have the status and headers been written to the underlying writer?
+	written bool
+}
+
+// Reset the responseWriter, so that it wraps a given writer.
+func (w *responseWriter) reset(writer http.ResponseWriter) {
+	w.ResponseWriter = writer
+	w.status = status.OK
+	w.size = 0
+	w.written = false
+}
+
+// Set the status that will be written.
+// Has no effect once the headers have been written.
+// Informational statuses, other than status.SwitchingProtocols, are written immediately.
+func (w *responseWriter) WriteHeader(code int) {
+	if code >= 100 && code < 200 && code != status.SwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+// Write the status and headers, if they have not been already.
+func (w *responseWriter) writeHeaderNow() {
+	if !w.written {
+		w.written = true
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+// Write a byte array to the body, writing the status and headers first.
+func (w *responseWriter) Write(body []byte) (int, error) {
+	w.writeHeaderNow()
+
+	n, err := w.ResponseWriter.Write(body)
+	w.size += n
+
+	return n, err
+}
+
+// Write a string to the body, writing the status and headers first.
+func (w *responseWriter) WriteString(body string) (int, error) {
+	w.writeHeaderNow()
+
+	n, err := io.WriteString(w.ResponseWriter, body)
+	w.size += n
+
+	return n, err
+}
+
+// Flush any buffered data to the client, writing the status and headers first.
+// Implements http.Flusher.
+func (w *responseWriter) Flush() {
+	w.writeHeaderNow()
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Take over the connection from the http server.
+// Implements http.Hijacker, returns http.ErrNotSupported if the underlying writer cannot be hijacked.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.written = true
+	}
+
+	return conn, rw, err
+}
+
+// Initiate a HTTP/2 server push.
+// Implements http.Pusher, returns http.ErrNotSupported if the underlying writer cannot push.
+func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := w.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+
+	return http.ErrNotSupported
+}
+
+// Get the underlying writer, used by http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Writer that keeps headers but discards anything else written to it.
+// Used as the writer of a copied Ctx.
+type discardWriter struct {
+	header http.Header
+}
+
+// Get the headers of the discardWriter.
+func (w *discardWriter) Header() http.Header {
+	return w.header
+}
+
+// Discards the status.
+func (w *discardWriter) WriteHeader(code int) {}
+
+// Discards the body, reporting it as written.
+func (w *discardWriter) Write(body []byte) (int, error) {
+	return len(body), nil
+}
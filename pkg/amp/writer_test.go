@@ -0,0 +1,95 @@
+package amp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joseph-beck/amp/pkg/status"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseWriterWriteHeader(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	writer := responseWriter{}
+	writer.reset(recorder)
+
+	writer.WriteHeader(status.Created)
+	assert.Equal(t, status.Created, writer.status)
+	assert.False(t, writer.written)
+	assert.False(t, recorder.Flushed)
+
+	writer.WriteHeader(status.Accepted)
+	assert.Equal(t, status.Accepted, writer.status)
+
+	writer.Header().Set("X-After", "value")
+
+	n, err := writer.Write([]byte("body"))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.True(t, writer.written)
+	assert.Equal(t, 4, writer.size)
+
+	// status can no longer be changed once written.
+	writer.WriteHeader(status.BadRequest)
+	assert.Equal(t, status.Accepted, writer.status)
+
+	assert.Equal(t, status.Accepted, recorder.Code)
+	assert.Equal(t, "value", recorder.Header().Get("X-After"))
+	assert.Equal(t, "body", recorder.Body.String())
+}
+
+func TestResponseWriterWriteString(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	writer := responseWriter{}
+	writer.reset(recorder)
+
+	n, err := writer.WriteString("body")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, 4, writer.size)
+	assert.Equal(t, status.OK, recorder.Code)
+}
+
+func TestResponseWriterFlush(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	writer := responseWriter{}
+	writer.reset(recorder)
+
+	writer.WriteHeader(status.Created)
+	http.Flusher(&writer).Flush()
+	assert.True(t, writer.written)
+	assert.True(t, recorder.Flushed)
+	assert.Equal(t, status.Created, recorder.Code)
+}
+
+func TestResponseWriterHijack(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	writer := responseWriter{}
+	writer.reset(recorder)
+
+	_, _, err := http.Hijacker(&writer).Hijack()
+	assert.ErrorIs(t, err, http.ErrNotSupported)
+	assert.False(t, writer.written)
+}
+
+func TestResponseWriterPush(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	writer := responseWriter{}
+	writer.reset(recorder)
+
+	err := http.Pusher(&writer).Push("/test", nil)
+	assert.ErrorIs(t, err, http.ErrNotSupported)
+}
+
+func TestResponseWriterUnwrap(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	writer := responseWriter{}
+	writer.reset(recorder)
+
+	assert.Equal(t, recorder, writer.Unwrap())
+
+	err := http.NewResponseController(&writer).Flush()
+	assert.NoError(t, err)
+	assert.True(t, recorder.Flushed)
+}
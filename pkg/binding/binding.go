@@ -15,6 +15,7 @@ import (
 	"sync"
 
 	"github.com/go-playground/validator/v10"
+	amperr "github.com/joseph-beck/amp/pkg/error"
 )
 
 type Binder interface {
@@ -22,11 +23,92 @@ type Binder interface {
 	Bind(*http.Request, any) error
 }
 
+// A Binder that can bind a request without validating the result.
+// Allows a struct to be bound from many sources, and then validated once, see Validate.
+// All of the Binders of this package are Decoders.
+type Decoder interface {
+	Binder
+	Decode(*http.Request, any) error
+}
+
+// Error given when a source could not be bound, when binding from many sources,
+// or when a field could not be bound from its value, such as "abc" for an int.
+// Returned by a Handler, it gives an error.BadRequest, or a 400, unless the error it wraps has a status.
+type BindError struct {
+	// Name of the Binder of the source, such as "uri", "query" or "json".
+	Source string
+
+	// Name of the field in the source, such as "page", empty if the whole source could not be bound.
+	Field string
+
+	// Error given by the Binder.
+	Err error
+}
+
+// Get the message of the BindError.
+func (e *BindError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("error, could not bind %s %q: %s", e.Source, e.Field, e.Err.Error())
+	}
+
+	return fmt.Sprintf("error, could not bind %s: %s", e.Source, e.Err.Error())
+}
+
+// Get the error given by the Binder.
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// Allows errors.As, and so error.From, to get an error.BadRequest from the BindError.
+// The source and field are given in the message, and as a detail of the error if there is a field.
+// If the error given by the Binder has a status, such as FieldErrors or a *http.MaxBytesError, that is used instead.
+func (e *BindError) As(target any) bool {
+	t, ok := target.(**amperr.Error)
+	if !ok {
+		return false
+	}
+
+	var inner *amperr.Error
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(e.Err, &inner) || errors.As(e.Err, &maxBytesErr) {
+		*t = amperr.From(e.Err)
+		return true
+	}
+
+	if e.Field == "" {
+		*t = amperr.BadRequest("could not bind " + e.Source).WithCause(e)
+		return true
+	}
+
+	*t = amperr.BadRequest(fmt.Sprintf("could not bind %s %q", e.Source, e.Field)).
+		WithDetail(e.Field, "is invalid", e.Source).
+		WithCause(e)
+	return true
+}
+
+var (
+	JSON      = jsonBinding{}
+	TOML      = tomlBinding{}
+	YAML      = yamlBinding{}
+	XML       = xmlBinding{}
+	QUERY     = queryBinding{}
+	URI       = uriBinding{}
+	HEADER    = headerBinding{}
+	COOKIE    = cookieBinding{}
+	FORM      = formBinding{}
+	MULTIPART = multipartBinding{}
+)
+
+// Strict Binders, which reject unknown fields, use them with Ctx.ShouldBindWith or Ctx.MustBindWith.
+//
+//	var obj Request
+//	if err := ctx.ShouldBindWith(&obj, binding.StrictJSON); err != nil {
+//		return err
+//	}
 var (
-	JSON = jsonBinding{}
-	TOML = tomlBinding{}
-	YAML = yamlBinding{}
-	XML  = xmlBinding{}
+	StrictJSON = jsonBinding{strict: true}
+	StrictTOML = tomlBinding{strict: true}
+	StrictYAML = yamlBinding{strict: true}
 )
 
 func readBody(request *http.Request) (*bytes.Buffer, error) {
@@ -52,15 +134,59 @@ type StructValidator interface {
 
 var Validator StructValidator = &defaultValidator{}
 
-func validate(obj any) error {
-	if Validator == nil {
+// Validate an obj with the Validator, returns nil if the Validator is nil.
+// Used after binding an obj with a Decoder.
+func Validate(obj any) error {
+	return validate(obj)
+}
+
+// Validate an obj with a given StructValidator, returns nil if the StructValidator is nil.
+// Errors of the StructValidator are given as FieldErrors, when they can be converted.
+func ValidateWith(v StructValidator, obj any) error {
+	if v == nil {
 		return nil
 	}
-	return Validator.ValidateStruct(obj)
+
+	err := v.ValidateStruct(obj)
+	if fieldErrs, ok := AsFieldErrors(err); ok {
+		return fieldErrs
+	}
+
+	return err
+}
+
+// Validates an obj with the Validator.
+func validate(obj any) error {
+	return ValidateWith(Validator, obj)
 }
 
 type SliceValidationError []error
 
+// An error of an element of a slice that failed validation, with the index of the element.
+type elementError struct {
+	index int
+	err   error
+}
+
+// Get the message of the error of the element.
+func (e elementError) Error() string {
+	return e.err.Error()
+}
+
+// Get the error of the element.
+func (e elementError) Unwrap() error {
+	return e.err
+}
+
+// Get the index of an error of a SliceValidationError, its position if the index is unknown.
+func sliceIndex(err error, i int) int {
+	if e, ok := err.(elementError); ok {
+		return e.index
+	}
+
+	return i
+}
+
 func (err SliceValidationError) Error() string {
 	n := len(err)
 
@@ -70,14 +196,14 @@ func (err SliceValidationError) Error() string {
 	default:
 		var builder strings.Builder
 		if err[0] != nil {
-			fmt.Fprintf(&builder, "[%d]: %s", 0, err[0].Error())
+			fmt.Fprintf(&builder, "[%d]: %s", sliceIndex(err[0], 0), err[0].Error())
 		}
 
 		if n > 1 {
 			for i := 1; i < n; i++ {
 				if err[i] != nil {
 					builder.WriteString("\n")
-					fmt.Fprintf(&builder, "[%d]: %s", i, err[i].Error())
+					fmt.Fprintf(&builder, "[%d]: %s", sliceIndex(err[i], i), err[i].Error())
 				}
 			}
 		}
@@ -95,6 +221,7 @@ func (v *defaultValidator) lazyInit() {
 	v.once.Do(func() {
 		v.validate = validator.New()
 		v.validate.SetTagName("binding")
+		v.validate.RegisterTagNameFunc(jsonName)
 	})
 }
 
@@ -119,7 +246,7 @@ func (v *defaultValidator) ValidateStruct(obj any) error {
 		validate := make(SliceValidationError, 0)
 		for i := 0; i < count; i++ {
 			if err := v.ValidateStruct(val.Index(i).Interface()); err != nil {
-				validate = append(validate, err)
+				validate = append(validate, elementError{index: i, err: err})
 			}
 		}
 
@@ -1,8 +1,15 @@
 package binding
 
 import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	amperr "github.com/joseph-beck/amp/pkg/error"
+	"github.com/joseph-beck/amp/pkg/status"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -49,3 +56,102 @@ func TestEngine(t *testing.T) {
 	engine := validator.Engine()
 	assert.NotNil(t, engine)
 }
+
+func TestDecoders(t *testing.T) {
+	binders := []Binder{JSON, TOML, YAML, XML, QUERY, URI, HEADER, COOKIE, FORM, MULTIPART}
+	for _, binder := range binders {
+		_, ok := binder.(Decoder)
+		assert.True(t, ok, binder.Name())
+	}
+
+	request, err := http.NewRequest("GET", "/test", strings.NewReader(`{}`))
+	assert.NoError(t, err)
+
+	var obj Mock
+	err = JSON.Decode(request, &obj)
+	assert.NoError(t, err)
+
+	err = Validate(&obj)
+	assert.Error(t, err)
+}
+
+func TestBindError(t *testing.T) {
+	cause := errors.New("cause")
+	err := error(&BindError{Source: "query", Err: cause})
+	assert.Equal(t, "error, could not bind query: cause", err.Error())
+	assert.ErrorIs(t, err, cause)
+
+	var bindErr *BindError
+	assert.ErrorAs(t, errors.Join(errors.New("other"), err), &bindErr)
+	assert.Equal(t, "query", bindErr.Source)
+
+	e := amperr.From(err)
+	assert.Equal(t, status.BadRequest, e.Status)
+	assert.Equal(t, "could not bind query", e.Message)
+	assert.Empty(t, e.Details)
+
+	err = &BindError{Source: "query", Field: "page", Err: cause}
+	assert.Equal(t, `error, could not bind query "page": cause`, err.Error())
+
+	e = amperr.From(err)
+	assert.Equal(t, status.BadRequest, e.Status)
+	assert.Equal(t, `could not bind query "page"`, e.Message)
+	assert.Equal(t, []amperr.Detail{{Field: "page", Message: "is invalid", Code: "query"}}, e.Details)
+
+	// errors that have a status keep it.
+	e = amperr.From(&BindError{Source: "json", Err: FieldErrors{{Field: "key", Message: "is required", Rule: "required"}}})
+	assert.Equal(t, status.UnprocessableContent, e.Status)
+
+	e = amperr.From(&BindError{Source: "json", Err: &http.MaxBytesError{Limit: 1}})
+	assert.Equal(t, status.PayloadTooLarge, e.Status)
+}
+
+func TestBindErrorSources(t *testing.T) {
+	type request struct {
+		Page  int       `query:"page" uri:"page" header:"page" cookie:"page" form:"page"`
+		Force bool      `query:"force" uri:"force" header:"force" cookie:"force" form:"force"`
+		Since time.Time `query:"since" uri:"since" header:"since" cookie:"since" form:"since"`
+	}
+
+	sources := map[Binder]func(key string, val string) *http.Request{
+		QUERY: func(key string, val string) *http.Request {
+			return httptest.NewRequest("GET", "/test?"+key+"="+val, nil)
+		},
+		URI: func(key string, val string) *http.Request {
+			request := httptest.NewRequest("GET", "/test", nil)
+			request.SetPathValue(key, val)
+			return request
+		},
+		HEADER: func(key string, val string) *http.Request {
+			request := httptest.NewRequest("GET", "/test", nil)
+			request.Header.Set(key, val)
+			return request
+		},
+		COOKIE: func(key string, val string) *http.Request {
+			request := httptest.NewRequest("GET", "/test", nil)
+			request.AddCookie(&http.Cookie{Name: key, Value: val})
+			return request
+		},
+		FORM: func(key string, val string) *http.Request {
+			request := httptest.NewRequest("POST", "/test", strings.NewReader(key+"="+val))
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			return request
+		},
+	}
+
+	for binder, newRequest := range sources {
+		for _, field := range []string{"page", "force", "since"} {
+			var obj request
+			err := binder.Bind(newRequest(field, "abc"), &obj)
+
+			var bindErr *BindError
+			assert.ErrorAs(t, err, &bindErr, binder.Name())
+			assert.Equal(t, binder.Name(), bindErr.Source)
+			assert.Equal(t, field, bindErr.Field)
+
+			e := amperr.From(err)
+			assert.Equal(t, status.BadRequest, e.Status, binder.Name())
+			assert.Equal(t, []amperr.Detail{{Field: field, Message: "is invalid", Code: binder.Name()}}, e.Details, binder.Name())
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Binding is used for binding data in modelling languages.
+package binding
+
+import (
+	"net/http"
+)
+
+type cookieBinding struct{}
+
+// Get the name of the cookie binder.
+func (c cookieBinding) Name() string {
+	return "cookie"
+}
+
+// Bind the cookies of a request to a given reference to a struct, using the cookie tags of its fields.
+func (c cookieBinding) Bind(request *http.Request, obj any) error {
+	if err := c.Decode(request, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+// Decode the request to a given reference to a struct, like Bind, without validating it.
+func (c cookieBinding) Decode(request *http.Request, obj any) error {
+	return mapValues(obj, "cookie", func(key string) ([]string, bool) {
+		cookies := request.CookiesNamed(key)
+
+		vals := make([]string, 0, len(cookies))
+		for _, cookie := range cookies {
+			vals = append(vals, cookie.Value)
+		}
+
+		return vals, len(vals) > 0
+	})
+}
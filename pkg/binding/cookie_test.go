@@ -0,0 +1,40 @@
+package binding
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockCookie struct {
+	Session string  `cookie:"session" binding:"required"`
+	Theme   *string `cookie:"theme"`
+}
+
+func TestCookieBindingName(t *testing.T) {
+	binder := cookieBinding{}
+	assert.Equal(t, "cookie", binder.Name())
+}
+
+func TestCookieBindingBind(t *testing.T) {
+	request, err := http.NewRequest("GET", "/test", nil)
+	assert.NoError(t, err)
+	request.AddCookie(&http.Cookie{Name: "session", Value: "value"})
+
+	var obj MockCookie
+	binder := cookieBinding{}
+	err = binder.Bind(request, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "value", obj.Session)
+	assert.Nil(t, obj.Theme)
+
+	request, err = http.NewRequest("GET", "/test", nil)
+	assert.NoError(t, err)
+	request.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+
+	obj = MockCookie{}
+	err = binder.Bind(request, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "dark", *obj.Theme)
+}
@@ -0,0 +1,260 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Binding is used for binding data in modelling languages.
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	amperr "github.com/joseph-beck/amp/pkg/error"
+)
+
+// A field that failed validation.
+type FieldError struct {
+	// Path of the field, using the names of its json tags, for example "items[0].name".
+	Field string `json:"field" toml:"field" yaml:"field" xml:"field"`
+
+	// Rule the field failed, for example "min".
+	Rule string `json:"rule" toml:"rule" yaml:"rule" xml:"rule"`
+
+	// Parameter of the rule, for example "3" for "min=3", empty if the rule has none.
+	Param string `json:"param,omitempty" toml:"param,omitempty" yaml:"param,omitempty" xml:"param,omitempty"`
+
+	// Human readable message, given by the Translation of the rule.
+	Message string `json:"message" toml:"message" yaml:"message" xml:"message"`
+
+	// Kind of the field, used to choose the default messages.
+	kind reflect.Kind
+}
+
+// Get the message of the FieldError, for example "name is required".
+func (e FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// Fields that failed validation.
+// Given by Binders when an obj fails validation, use AsFieldErrors to get them from an error.
+// Returned by a Handler, they give an error.UnprocessableContent, or a 422, with a detail for each field.
+type FieldErrors []FieldError
+
+// Get the messages of the FieldErrors.
+func (e FieldErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		msgs = append(msgs, fieldErr.Error())
+	}
+
+	return strings.Join(msgs, ", ")
+}
+
+// Allows errors.As, and so error.From, to get an error.UnprocessableContent from the FieldErrors.
+// The Field, Message and Rule of each FieldError are given as the details of the error.
+func (e FieldErrors) As(target any) bool {
+	t, ok := target.(**amperr.Error)
+	if !ok {
+		return false
+	}
+
+	err := amperr.UnprocessableContent("validation failed").WithCause(e)
+	for _, fieldErr := range e {
+		err.Details = append(err.Details, amperr.Detail{Field: fieldErr.Field, Message: fieldErr.Message, Code: fieldErr.Rule})
+	}
+
+	*t = err
+	return true
+}
+
+// Get the FieldErrors of an error given by the Validator, or a Binder.
+// Errors of the default Validator are converted, including those of a SliceValidationError.
+// Returns false if the error has no FieldErrors.
+//
+//	if fieldErrs, ok := binding.AsFieldErrors(err); ok {
+//		return ctx.RenderJSON(status.UnprocessableContent, fieldErrs)
+//	}
+func AsFieldErrors(err error) (FieldErrors, bool) {
+	if err == nil {
+		return nil, false
+	}
+
+	var fieldErrs FieldErrors
+	if errors.As(err, &fieldErrs) {
+		return fieldErrs, true
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return fromValidationErrors(validationErrs), true
+	}
+
+	var sliceErrs SliceValidationError
+	if !errors.As(err, &sliceErrs) {
+		return nil, false
+	}
+
+	fieldErrs = make(FieldErrors, 0, len(sliceErrs))
+	for i, sliceErr := range sliceErrs {
+		inner, ok := AsFieldErrors(sliceErr)
+		if !ok {
+			return nil, false
+		}
+
+		prefix := fmt.Sprintf("[%d]", sliceIndex(sliceErr, i))
+		for _, fieldErr := range inner {
+			fieldErr.Field = joinPath(prefix, fieldErr.Field)
+			fieldErrs = append(fieldErrs, fieldErr)
+		}
+	}
+
+	return fieldErrs, true
+}
+
+// Converts the errors of the validator to FieldErrors.
+func fromValidationErrors(errs validator.ValidationErrors) FieldErrors {
+	fieldErrs := make(FieldErrors, 0, len(errs))
+	for _, err := range errs {
+		fieldErr := FieldError{
+			Field: fieldPath(err),
+			Rule:  err.Tag(),
+			Param: err.Param(),
+			kind:  err.Kind(),
+		}
+
+		fieldErr.Message = translate(fieldErr, err.ActualTag())
+		fieldErrs = append(fieldErrs, fieldErr)
+	}
+
+	return fieldErrs
+}
+
+// Gets the path of a field that failed validation, without the name of the struct that was validated.
+func fieldPath(err validator.FieldError) string {
+	_, path, ok := strings.Cut(err.Namespace(), ".")
+	if !ok {
+		return err.Field()
+	}
+
+	return path
+}
+
+// Joins the path of a field to a prefix, such as the index of a slice.
+func joinPath(prefix string, path string) string {
+	if prefix == "" || strings.HasPrefix(path, "[") {
+		return prefix + path
+	}
+
+	return prefix + "." + path
+}
+
+// Gets the name of a field used by the validator, the name of its json tag if it has one.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" || name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+// Amp binding Translation.
+// Gives the human readable message of a FieldError, such as "must be at least 3 characters long".
+// The message is given without the name of the field.
+type Translation func(err FieldError) string
+
+// Translations keyed by the rule they translate, used when creating FieldErrors.
+var (
+	translations = map[string]Translation{
+		"required": message("is required"),
+		"email":    message("must be a valid email address"),
+		"url":      message("must be a valid URL"),
+		"uri":      message("must be a valid URI"),
+		"uuid":     message("must be a valid UUID"),
+		"alpha":    message("must only contain letters"),
+		"alphanum": message("must only contain letters and numbers"),
+		"numeric":  message("must be a number"),
+		"boolean":  message("must be a boolean"),
+		"ip":       message("must be a valid IP address"),
+		"datetime": func(err FieldError) string {
+			return fmt.Sprintf("must be a date time in the format %q", err.Param)
+		},
+		"oneof": func(err FieldError) string {
+			return "must be one of " + strings.Join(strings.Fields(err.Param), ", ")
+		},
+		"eq": func(err FieldError) string {
+			return "must be equal to " + err.Param
+		},
+		"ne": func(err FieldError) string {
+			return "must not be equal to " + err.Param
+		},
+		"len": bound("must be", "exactly"),
+		"min": bound("must be at least", "at least"),
+		"max": bound("must be at most", "at most"),
+		"gt":  bound("must be greater than", "more than"),
+		"gte": bound("must be at least", "at least"),
+		"lt":  bound("must be less than", "less than"),
+		"lte": bound("must be at most", "at most"),
+	}
+
+	translationsMu sync.RWMutex
+)
+
+// Register a Translation for a rule, such as "min", used for the messages of FieldErrors.
+// Replaces any Translation already registered for the rule, allowing messages to be customised.
+//
+//	binding.RegisterTranslation("required", func(err binding.FieldError) string {
+//		return "est obligatoire"
+//	})
+func RegisterTranslation(rule string, translation Translation) {
+	translationsMu.Lock()
+	defer translationsMu.Unlock()
+
+	translations[rule] = translation
+}
+
+// Gets the message of a FieldError using the Translation of its rule.
+// If the rule is an alias without a Translation, the Translation of the actual rule that failed is used.
+// Rules without a Translation are given a generic message.
+func translate(err FieldError, actual ...string) string {
+	translationsMu.RLock()
+	defer translationsMu.RUnlock()
+
+	if translation, ok := translations[err.Rule]; ok {
+		return translation(err)
+	}
+
+	for _, rule := range actual {
+		if translation, ok := translations[rule]; ok {
+			return translation(err)
+		}
+	}
+
+	return fmt.Sprintf("failed on the %q rule", err.Rule)
+}
+
+// Creates a Translation that always gives the same message.
+func message(msg string) Translation {
+	return func(err FieldError) string {
+		return msg
+	}
+}
+
+// Creates a Translation for a rule that bounds a field, using a length for strings, slices and maps.
+//
+//	"must be at least 3", "must have at least 3 items", "must be at least 3 characters long"
+func bound(number string, length string) Translation {
+	return func(err FieldError) string {
+		switch err.kind {
+		case reflect.String:
+			return fmt.Sprintf("must be %s %s characters long", length, err.Param)
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return fmt.Sprintf("must have %s %s items", length, err.Param)
+		default:
+			return fmt.Sprintf("%s %s", number, err.Param)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package binding
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	amperr "github.com/joseph-beck/amp/pkg/error"
+	"github.com/joseph-beck/amp/pkg/status"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockItem struct {
+	Name string   `json:"name" binding:"required"`
+	Tags []string `json:"tags" binding:"max=1"`
+}
+
+type MockOrder struct {
+	Email    string     `json:"email" binding:"required,email"`
+	Age      int        `json:"age" binding:"min=18"`
+	Code     string     `json:"code" binding:"len=4"`
+	Priority string     `json:"priority" binding:"oneof=low high"`
+	Items    []MockItem `json:"items" binding:"dive"`
+	Internal string     `json:"-" binding:"required"`
+}
+
+func TestAsFieldErrors(t *testing.T) {
+	validator := &defaultValidator{}
+
+	err := validator.ValidateStruct(MockOrder{
+		Email:    "invalid",
+		Age:      10,
+		Code:     "abc",
+		Priority: "none",
+		Items:    []MockItem{{Name: "name"}, {Tags: []string{"a", "b"}}},
+	})
+	assert.Error(t, err)
+
+	fieldErrs, ok := AsFieldErrors(err)
+	assert.True(t, ok)
+	assert.Equal(t, FieldErrors{
+		{Field: "email", Rule: "email", Message: "must be a valid email address"},
+		{Field: "age", Rule: "min", Param: "18", Message: "must be at least 18"},
+		{Field: "code", Rule: "len", Param: "4", Message: "must be exactly 4 characters long"},
+		{Field: "priority", Rule: "oneof", Param: "low high", Message: "must be one of low, high"},
+		{Field: "items[1].name", Rule: "required", Message: "is required"},
+		{Field: "items[1].tags", Rule: "max", Param: "1", Message: "must have at most 1 items"},
+		{Field: "Internal", Rule: "required", Message: "is required"},
+	}, withoutKind(fieldErrs))
+
+	err = validator.ValidateStruct([]MockItem{{Name: "name"}, {}, {}})
+	fieldErrs, ok = AsFieldErrors(err)
+	assert.True(t, ok)
+	assert.Len(t, fieldErrs, 2)
+	assert.Equal(t, "[1].name", fieldErrs[0].Field)
+	assert.Equal(t, "[2].name", fieldErrs[1].Field)
+	assert.Equal(t, "[1].name is required, [2].name is required", fieldErrs.Error())
+
+	_, ok = AsFieldErrors(errors.New("error"))
+	assert.False(t, ok)
+
+	_, ok = AsFieldErrors(nil)
+	assert.False(t, ok)
+
+	fieldErrs, ok = AsFieldErrors(&BindError{Source: "json", Err: FieldErrors{{Field: "key"}}})
+	assert.True(t, ok)
+	assert.Len(t, fieldErrs, 1)
+}
+
+func TestFieldErrorsAs(t *testing.T) {
+	request, err := http.NewRequest("POST", "/test", strings.NewReader(`{}`))
+	assert.NoError(t, err)
+
+	var obj Mock
+	err = JSON.Bind(request, &obj)
+	assert.Error(t, err)
+
+	var fieldErrs FieldErrors
+	assert.ErrorAs(t, err, &fieldErrs)
+
+	e := amperr.From(err)
+	assert.Equal(t, status.UnprocessableContent, e.Status)
+	assert.Equal(t, []amperr.Detail{{Field: "key", Message: "is required", Code: "required"}}, e.Details)
+	assert.ErrorAs(t, e.Unwrap(), &fieldErrs)
+}
+
+func TestRegisterTranslation(t *testing.T) {
+	RegisterTranslation("custom", func(err FieldError) string {
+		return "custom " + err.Param
+	})
+	defer func() {
+		translationsMu.Lock()
+		delete(translations, "custom")
+		translationsMu.Unlock()
+	}()
+
+	assert.Equal(t, "custom param", translate(FieldError{Rule: "custom", Param: "param"}))
+	assert.Equal(t, `failed on the "unknown" rule`, translate(FieldError{Rule: "unknown"}))
+}
+
+// Removes the kind of each FieldError, so that they can be compared.
+func withoutKind(fieldErrs FieldErrors) FieldErrors {
+	for i := range fieldErrs {
+		fieldErrs[i].kind = 0
+	}
+
+	return fieldErrs
+}
@@ -0,0 +1,63 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Binding is used for binding data in modelling languages.
+package binding
+
+import (
+	"mime"
+	"net/http"
+)
+
+// Maximum bytes of a multipart form that are kept in memory, the rest of the files are stored on disk.
+// Used by the FORM and MULTIPART binders if the form of the request has not already been parsed.
+const DefaultMaxMultipartMemory = 32 << 20
+
+type formBinding struct{}
+
+// Get the name of the form binder.
+func (f formBinding) Name() string {
+	return "form"
+}
+
+// Bind the form of a request to a given reference to a struct, using the form tags of its fields.
+// Both the query and the body, url encoded or multipart, are bound, the body takes precedence.
+func (f formBinding) Bind(request *http.Request, obj any) error {
+	if err := f.Decode(request, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+// Decode the request to a given reference to a struct, like Bind, without validating it.
+func (f formBinding) Decode(request *http.Request, obj any) error {
+	if err := parseForm(request); err != nil {
+		return err
+	}
+
+	return mapValues(obj, "form", func(key string) ([]string, bool) {
+		vals, ok := request.Form[key]
+		return vals, ok
+	})
+}
+
+// Parses the form of a request, as a multipart form if it is one.
+// The url encoded form is parsed first, as ParseMultipartForm hides its errors when the body is not multipart.
+func parseForm(request *http.Request) error {
+	if err := request.ParseForm(); err != nil {
+		return err
+	}
+
+	if !isMultipart(request) {
+		return nil
+	}
+
+	return request.ParseMultipartForm(DefaultMaxMultipartMemory)
+}
+
+// Whether the body of a request is a multipart form.
+func isMultipart(request *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	return err == nil && mediaType == "multipart/form-data"
+}
@@ -0,0 +1,62 @@
+package binding
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockForm struct {
+	Key  string   `form:"key" binding:"required"`
+	Tags []string `form:"tag"`
+	Page int      `form:"page" default:"1"`
+}
+
+func TestFormBindingName(t *testing.T) {
+	binder := formBinding{}
+	assert.Equal(t, "form", binder.Name())
+}
+
+func TestFormBindingBind(t *testing.T) {
+	request, err := http.NewRequest("POST", "/test?page=2", strings.NewReader("key=value&tag=a&tag=b"))
+	assert.NoError(t, err)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var obj MockForm
+	binder := formBinding{}
+	err = binder.Bind(request, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "value", obj.Key)
+	assert.Equal(t, []string{"a", "b"}, obj.Tags)
+	assert.Equal(t, 2, obj.Page)
+
+	request, err = http.NewRequest("POST", "/test", strings.NewReader("tag=a"))
+	assert.NoError(t, err)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	obj = MockForm{}
+	err = binder.Bind(request, &obj)
+	assert.Error(t, err)
+
+	request, err = http.NewRequest("POST", "/test", strings.NewReader("key=value&page=one"))
+	assert.NoError(t, err)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	obj = MockForm{}
+	err = binder.Bind(request, &obj)
+	assert.Error(t, err)
+
+	// a body that is too large is not partially bound.
+	request, err = http.NewRequest("POST", "/test", strings.NewReader("key=value&tag=a"))
+	assert.NoError(t, err)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Body = http.MaxBytesReader(nil, request.Body, 4)
+
+	obj = MockForm{}
+	err = binder.Bind(request, &obj)
+	var maxBytesErr *http.MaxBytesError
+	assert.ErrorAs(t, err, &maxBytesErr)
+	assert.Empty(t, obj.Key)
+}
@@ -0,0 +1,34 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Binding is used for binding data in modelling languages.
+package binding
+
+import (
+	"net/http"
+)
+
+type headerBinding struct{}
+
+// Get the name of the header binder.
+func (h headerBinding) Name() string {
+	return "header"
+}
+
+// Bind the headers of a request to a given reference to a struct, using the header tags of its fields.
+// Header names are not case sensitive, repeated headers are bound to slices.
+func (h headerBinding) Bind(request *http.Request, obj any) error {
+	if err := h.Decode(request, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+// Decode the request to a given reference to a struct, like Bind, without validating it.
+func (h headerBinding) Decode(request *http.Request, obj any) error {
+	return mapValues(obj, "header", func(key string) ([]string, bool) {
+		vals := request.Header.Values(key)
+		return vals, len(vals) > 0
+	})
+}
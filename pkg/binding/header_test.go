@@ -0,0 +1,43 @@
+package binding
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockHeader struct {
+	Token   string        `header:"X-Token" binding:"required"`
+	Accept  []string      `header:"Accept"`
+	Timeout time.Duration `header:"X-Timeout" default:"5s"`
+}
+
+func TestHeaderBindingName(t *testing.T) {
+	binder := headerBinding{}
+	assert.Equal(t, "header", binder.Name())
+}
+
+func TestHeaderBindingBind(t *testing.T) {
+	request, err := http.NewRequest("GET", "/test", nil)
+	assert.NoError(t, err)
+	request.Header.Set("x-token", "value")
+	request.Header.Add("Accept", "application/json")
+	request.Header.Add("Accept", "application/xml")
+
+	var obj MockHeader
+	binder := headerBinding{}
+	err = binder.Bind(request, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "value", obj.Token)
+	assert.Equal(t, []string{"application/json", "application/xml"}, obj.Accept)
+	assert.Equal(t, 5*time.Second, obj.Timeout)
+
+	request, err = http.NewRequest("GET", "/test", nil)
+	assert.NoError(t, err)
+
+	obj = MockHeader{}
+	err = binder.Bind(request, &obj)
+	assert.Error(t, err)
+}
@@ -7,11 +7,16 @@ package binding
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 )
 
-type jsonBinding struct{}
+type jsonBinding struct {
+	// Rejects unknown fields, duplicate keys and data after the JSON value.
+	strict bool
+}
 
 // Get the name of the JSON binder.
 func (j jsonBinding) Name() string {
@@ -20,6 +25,15 @@ func (j jsonBinding) Name() string {
 
 // Bind a request to a given reference to any object, errors if cannot decode struct.
 func (j jsonBinding) Bind(request *http.Request, obj any) error {
+	if err := j.Decode(request, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+// Decode a request to a given reference to any object, without validating it.
+func (j jsonBinding) Decode(request *http.Request, obj any) error {
 	buff, err := readBody(request)
 	if err != nil {
 		return err
@@ -30,22 +44,83 @@ func (j jsonBinding) Bind(request *http.Request, obj any) error {
 
 // Binds the a byte array to an object of type any.
 func (j jsonBinding) BindBody(body []byte, obj any) error {
-	return j.decodeJSON(bytes.NewReader(body), obj)
+	if err := j.decodeJSON(bytes.NewReader(body), obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+// Get a strict JSON binder, which rejects unknown fields, duplicate keys and data after the JSON value.
+// The same as StrictJSON, register it to make Ctx.Bind strict, binding.Register("application/json", binding.StrictJSON).
+func (j jsonBinding) Strict() Decoder {
+	return StrictJSON
 }
 
-// Decodes a reader with an object of any, errors if it cannot validate the struct.
+// Decodes a reader with an object of any.
 func (j jsonBinding) decodeJSON(reader io.Reader, obj any) error {
-	decoder := json.NewDecoder(reader)
+	if !j.strict {
+		decoder := json.NewDecoder(reader)
+		return decoder.Decode(obj)
+	}
 
-	err := decoder.Decode(obj)
+	data, err := io.ReadAll(reader)
 	if err != nil {
 		return err
 	}
 
-	err = validate(obj)
-	if err != nil {
+	if err := checkDuplicateKeys(json.NewDecoder(bytes.NewReader(data))); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(obj); err != nil {
 		return err
 	}
 
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("error, unexpected data after JSON value")
+	}
+
 	return nil
 }
+
+// Checks that no object of the next JSON value of a decoder has the same key more than once.
+func checkDuplicateKeys(decoder *json.Decoder) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return nil
+	}
+
+	keys := make(map[string]struct{})
+	for decoder.More() {
+		if delim == '{' {
+			token, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+
+			key, _ := token.(string)
+			if _, ok := keys[key]; ok {
+				return fmt.Errorf("error, duplicate JSON key %q", key)
+			}
+
+			keys[key] = struct{}{}
+		}
+
+		if err := checkDuplicateKeys(decoder); err != nil {
+			return err
+		}
+	}
+
+	// the closing delimiter of the object or array.
+	_, err = decoder.Token()
+	return err
+}
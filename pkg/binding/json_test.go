@@ -3,6 +3,7 @@ package binding
 import (
 	"bytes"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -65,3 +66,42 @@ func TestJSONBindingBindBody(t *testing.T) {
 	assert.Equal(t, "value", val["key"])
 	assert.Equal(t, "field", val["other"])
 }
+
+func TestJSONBindingStrict(t *testing.T) {
+	binder := JSON.Strict().(jsonBinding)
+	assert.Equal(t, "json", binder.Name())
+
+	var obj Mock
+	err := binder.BindBody([]byte(`{"key": "value"}`), &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "value", obj.Key)
+
+	request, err := http.NewRequest("POST", "/test", strings.NewReader(`{"key": "value", "nested": [{"a": 1}, {"a": 2}]}`))
+	assert.NoError(t, err)
+	obj = Mock{}
+	err = binder.Bind(request, &obj)
+	assert.Error(t, err)
+
+	bodies := []string{
+		`{"key": "value", "other": "field"}`,
+		`{"key": "value", "key": "again"}`,
+		`{"key": "value"} {"key": "value"}`,
+		`{"key": "value"} trailing`,
+	}
+
+	for _, body := range bodies {
+		obj = Mock{}
+		err = binder.BindBody([]byte(body), &obj)
+		assert.Error(t, err, body)
+
+		err = JSON.BindBody([]byte(body), &Mock{})
+		assert.NoError(t, err, body)
+	}
+
+	val := make(map[string]any)
+	err = binder.BindBody([]byte(`{"a": {"b": 1}, "c": {"b": 2}, "d": [{"b": 1}, {"b": 2}]}`), &val)
+	assert.NoError(t, err)
+
+	err = binder.BindBody([]byte(`{"a": {"b": 1, "b": 2}}`), &val)
+	assert.ErrorContains(t, err, `duplicate JSON key "b"`)
+}
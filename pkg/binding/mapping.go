@@ -0,0 +1,282 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Binding is used for binding data in modelling languages.
+package binding
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	timeType            = reflect.TypeFor[time.Time]()
+	durationType        = reflect.TypeFor[time.Duration]()
+	fileHeaderType      = reflect.TypeFor[multipart.FileHeader]()
+)
+
+// Gets the values of a key from a source, such as the query of a request.
+// Returns false if the source does not have the key.
+type valuesFunc func(key string) ([]string, bool)
+
+// Gets the files of a key from a source, such as the multipart form of a request.
+// Returns false if the source does not have the key.
+type filesFunc func(key string) ([]*multipart.FileHeader, bool)
+
+// Binds the values of a source to the fields of a struct, using the names given by a tag.
+// The obj must be a pointer to a struct.
+//
+//	type Filter struct {
+//		Page  int       `query:"page" default:"1"`
+//		Tags  []string  `query:"tag"`
+//		Since time.Time `query:"since" time_format:"2006-01-02"`
+//	}
+//
+// Fields without the tag, or with the tag "-", are skipped,
+// unless they are structs, such as embedded structs, whose fields are then bound.
+// If the source does not have a key, the default tag of the field is used if it has one and the field is zero.
+func mapValues(obj any, tag string, values valuesFunc) error {
+	return mapFiles(obj, tag, values, nil)
+}
+
+// Binds the values and files of a source to the fields of a struct, like mapValues.
+// Fields of type multipart.FileHeader, *multipart.FileHeader, or slices of them, are bound from the files.
+//
+//	type Upload struct {
+//		Name   string                  `form:"name"`
+//		Avatar *multipart.FileHeader   `form:"avatar"`
+//		Photos []*multipart.FileHeader `form:"photos"`
+//	}
+func mapFiles(obj any, tag string, values valuesFunc, files filesFunc) error {
+	val := reflect.ValueOf(obj)
+	if val.Kind() != reflect.Pointer || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return errors.New("error, can only bind to a pointer to a struct")
+	}
+
+	return mapStruct(val.Elem(), tag, values, files)
+}
+
+// Binds the values and files of a source to the fields of a struct value.
+func mapStruct(val reflect.Value, tag string, values valuesFunc, files filesFunc) error {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, ok := field.Tag.Lookup(tag)
+		name, _, _ = strings.Cut(name, ",")
+		if name == "-" {
+			continue
+		}
+
+		if !ok || name == "" {
+			// lets bind the fields of untagged structs, such as embedded structs.
+			if isStruct(field.Type) {
+				if err := mapStruct(val.Field(i), tag, values, files); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if isFile(field.Type) {
+			if files == nil {
+				continue
+			}
+
+			fhs, ok := files(name)
+			if !ok || len(fhs) == 0 {
+				continue
+			}
+
+			setFiles(val.Field(i), fhs)
+			continue
+		}
+
+		vals, ok := values(name)
+		if !ok || len(vals) == 0 {
+			// defaults do not replace a value already set, such as by another source.
+			def, ok := field.Tag.Lookup("default")
+			if !ok || !val.Field(i).IsZero() {
+				continue
+			}
+
+			vals = []string{def}
+			if isSlice(field.Type) {
+				vals = strings.Split(def, ",")
+			}
+		}
+
+		if err := setField(val.Field(i), field, vals); err != nil {
+			return &BindError{Source: tag, Field: name, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// Checks if a type is a struct that is not bound from text.
+func isStruct(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ != timeType && typ != fileHeaderType && !reflect.PointerTo(typ).Implements(textUnmarshalerType)
+}
+
+// Checks if a type is bound from files, a multipart.FileHeader, a pointer to one, or a slice of either.
+func isFile(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	return typ == fileHeaderType
+}
+
+// Sets a field from its files, slices are given every file, other fields the first.
+func setFiles(val reflect.Value, fhs []*multipart.FileHeader) {
+	if val.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(val.Type(), len(fhs), len(fhs))
+		for i, fh := range fhs {
+			setFile(slice.Index(i), fh)
+		}
+
+		val.Set(slice)
+		return
+	}
+
+	setFile(val, fhs[0])
+}
+
+// Sets a multipart.FileHeader, or a pointer to one, from a file.
+func setFile(val reflect.Value, fh *multipart.FileHeader) {
+	if val.Kind() == reflect.Pointer {
+		val.Set(reflect.ValueOf(fh))
+		return
+	}
+
+	val.Set(reflect.ValueOf(*fh))
+}
+
+// Checks if a type is a slice that is not bound from a single value.
+func isSlice(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8
+}
+
+// Sets a field from its values, slices are given every value, other fields the first.
+func setField(val reflect.Value, field reflect.StructField, vals []string) error {
+	if isSlice(field.Type) {
+		slice := reflect.MakeSlice(field.Type, len(vals), len(vals))
+		for i, v := range vals {
+			if err := setValue(slice.Index(i), field, v); err != nil {
+				return err
+			}
+		}
+
+		val.Set(slice)
+		return nil
+	}
+
+	return setValue(val, field, vals[0])
+}
+
+// Sets a value from a string, allocating pointers and using encoding.TextUnmarshaler if implemented.
+func setValue(val reflect.Value, field reflect.StructField, s string) error {
+	if val.Kind() == reflect.Pointer {
+		ptr := reflect.New(val.Type().Elem())
+		if err := setValue(ptr.Elem(), field, s); err != nil {
+			return err
+		}
+
+		val.Set(ptr)
+		return nil
+	}
+
+	if val.CanAddr() && val.Addr().Type().Implements(textUnmarshalerType) && val.Type() != timeType {
+		return val.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch val.Type() {
+	case timeType:
+		layout := field.Tag.Get("time_format")
+		if layout == "" {
+			layout = time.RFC3339
+		}
+
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return err
+		}
+
+		val.Set(reflect.ValueOf(t))
+		return nil
+
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+
+		val.SetInt(int64(d))
+		return nil
+	}
+
+	switch val.Kind() {
+	case reflect.String:
+		val.SetString(s)
+
+	case reflect.Slice:
+		if val.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type %s", val.Type())
+		}
+
+		val.SetBytes([]byte(s))
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+
+		val.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, val.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		val.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, val.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		val.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, val.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		val.SetFloat(f)
+
+	default:
+		return fmt.Errorf("unsupported type %s", val.Type())
+	}
+
+	return nil
+}
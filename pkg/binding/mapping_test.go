@@ -0,0 +1,129 @@
+package binding
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockEmbedded struct {
+	Embedded string `query:"embedded"`
+}
+
+type MockMapping struct {
+	MockEmbedded
+
+	String   string        `query:"string"`
+	Int      int           `query:"int"`
+	Int8     int8          `query:"int8"`
+	Uint     uint          `query:"uint"`
+	Float    float64       `query:"float"`
+	Bool     bool          `query:"bool"`
+	Bytes    []byte        `query:"bytes"`
+	Pointer  *int          `query:"pointer"`
+	Nil      *int          `query:"nil"`
+	Slice    []int         `query:"slice"`
+	Pointers []*string     `query:"pointers"`
+	Time     time.Time     `query:"time"`
+	Date     time.Time     `query:"date" time_format:"2006-01-02"`
+	Duration time.Duration `query:"duration"`
+	Text     netip.Addr    `query:"text"`
+	Default  int           `query:"default" default:"10"`
+	Defaults []string      `query:"defaults" default:"a,b"`
+	Skipped  string        `query:"-"`
+	Untagged string
+	Inner    struct{ V int }
+}
+
+func TestMapValues(t *testing.T) {
+	values := map[string][]string{
+		"embedded": {"embedded"},
+		"string":   {"string"},
+		"int":      {"-1"},
+		"int8":     {"8"},
+		"uint":     {"1"},
+		"float":    {"1.5"},
+		"bool":     {"true"},
+		"bytes":    {"bytes"},
+		"pointer":  {"2"},
+		"slice":    {"1", "2", "3"},
+		"pointers": {"a", "b"},
+		"time":     {"2024-01-02T03:04:05Z"},
+		"date":     {"2024-01-02"},
+		"duration": {"1m30s"},
+		"text":     {"192.0.2.1"},
+		"-":        {"skipped"},
+		"Untagged": {"untagged"},
+	}
+
+	var obj MockMapping
+	err := mapValues(&obj, "query", func(key string) ([]string, bool) {
+		vals, ok := values[key]
+		return vals, ok
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "embedded", obj.Embedded)
+	assert.Equal(t, "string", obj.String)
+	assert.Equal(t, -1, obj.Int)
+	assert.Equal(t, int8(8), obj.Int8)
+	assert.Equal(t, uint(1), obj.Uint)
+	assert.Equal(t, 1.5, obj.Float)
+	assert.True(t, obj.Bool)
+	assert.Equal(t, []byte("bytes"), obj.Bytes)
+	assert.Equal(t, 2, *obj.Pointer)
+	assert.Nil(t, obj.Nil)
+	assert.Equal(t, []int{1, 2, 3}, obj.Slice)
+	assert.Len(t, obj.Pointers, 2)
+	assert.Equal(t, "b", *obj.Pointers[1])
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), obj.Time)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), obj.Date)
+	assert.Equal(t, 90*time.Second, obj.Duration)
+	assert.Equal(t, netip.MustParseAddr("192.0.2.1"), obj.Text)
+	assert.Equal(t, 10, obj.Default)
+	assert.Equal(t, []string{"a", "b"}, obj.Defaults)
+	assert.Equal(t, "", obj.Skipped)
+	assert.Equal(t, "", obj.Untagged)
+
+	// defaults do not replace fields that are already set.
+	obj = MockMapping{Default: 5}
+	err = mapValues(&obj, "query", func(key string) ([]string, bool) {
+		return nil, false
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, obj.Default)
+	assert.Equal(t, []string{"a", "b"}, obj.Defaults)
+}
+
+func TestMapValuesErrors(t *testing.T) {
+	values := func(key string) ([]string, bool) {
+		return []string{"invalid"}, true
+	}
+
+	var obj struct {
+		Int int `query:"int"`
+	}
+
+	err := mapValues(&obj, "query", values)
+	assert.ErrorContains(t, err, `query "int"`)
+
+	err = mapValues(obj, "query", values)
+	assert.Error(t, err)
+
+	var unsupported struct {
+		Map map[string]string `query:"map"`
+	}
+
+	err = mapValues(&unsupported, "query", values)
+	assert.Error(t, err)
+
+	var int8s struct {
+		Int8 int8 `query:"int8"`
+	}
+
+	err = mapValues(&int8s, "query", func(key string) ([]string, bool) {
+		return []string{"300"}, true
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,46 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Binding is used for binding data in modelling languages.
+package binding
+
+import (
+	"mime/multipart"
+	"net/http"
+)
+
+type multipartBinding struct{}
+
+// Get the name of the multipart binder.
+func (m multipartBinding) Name() string {
+	return "multipart"
+}
+
+// Bind the multipart form of a request to a given reference to a struct, using the form tags of its fields.
+// Fields of type *multipart.FileHeader, or []*multipart.FileHeader, are bound to the files of the form.
+func (m multipartBinding) Bind(request *http.Request, obj any) error {
+	if err := m.Decode(request, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+// Decode the request to a given reference to a struct, like Bind, without validating it.
+func (m multipartBinding) Decode(request *http.Request, obj any) error {
+	if err := request.ParseMultipartForm(DefaultMaxMultipartMemory); err != nil {
+		return err
+	}
+
+	values := func(key string) ([]string, bool) {
+		vals, ok := request.Form[key]
+		return vals, ok
+	}
+
+	files := func(key string) ([]*multipart.FileHeader, bool) {
+		fhs, ok := request.MultipartForm.File[key]
+		return fhs, ok
+	}
+
+	return mapFiles(obj, "form", values, files)
+}
@@ -0,0 +1,87 @@
+package binding
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockMultipart struct {
+	Name   string                  `form:"name" binding:"required"`
+	Avatar *multipart.FileHeader   `form:"avatar" binding:"required"`
+	Photos []*multipart.FileHeader `form:"photos"`
+	Other  multipart.FileHeader    `form:"other"`
+}
+
+func newMultipartRequest(t *testing.T, fields map[string]string, files map[string][]string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	for key, val := range fields {
+		assert.NoError(t, writer.WriteField(key, val))
+	}
+
+	for key, contents := range files {
+		for i, content := range contents {
+			part, err := writer.CreateFormFile(key, key+string(rune('a'+i))+".txt")
+			assert.NoError(t, err)
+
+			_, err = part.Write([]byte(content))
+			assert.NoError(t, err)
+		}
+	}
+
+	assert.NoError(t, writer.Close())
+
+	request, err := http.NewRequest("POST", "/test", body)
+	assert.NoError(t, err)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return request
+}
+
+func TestMultipartBindingName(t *testing.T) {
+	binder := multipartBinding{}
+	assert.Equal(t, "multipart", binder.Name())
+}
+
+func TestMultipartBindingBind(t *testing.T) {
+	request := newMultipartRequest(t,
+		map[string]string{"name": "value"},
+		map[string][]string{"avatar": {"avatar"}, "photos": {"one", "two"}, "other": {"other"}},
+	)
+
+	var obj MockMultipart
+	binder := multipartBinding{}
+	err := binder.Bind(request, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "value", obj.Name)
+	assert.Equal(t, "avatara.txt", obj.Avatar.Filename)
+	assert.Len(t, obj.Photos, 2)
+	assert.Equal(t, "othera.txt", obj.Other.Filename)
+
+	file, err := obj.Photos[1].Open()
+	assert.NoError(t, err)
+	content, err := io.ReadAll(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "two", string(content))
+	assert.NoError(t, file.Close())
+
+	request = newMultipartRequest(t, map[string]string{"name": "value"}, nil)
+
+	obj = MockMultipart{}
+	err = binder.Bind(request, &obj)
+	assert.Error(t, err)
+
+	request, err = http.NewRequest("POST", "/test", bytes.NewBufferString("name=value"))
+	assert.NoError(t, err)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	obj = MockMultipart{}
+	err = binder.Bind(request, &obj)
+	assert.Error(t, err)
+}
@@ -0,0 +1,36 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Binding is used for binding data in modelling languages.
+package binding
+
+import (
+	"net/http"
+)
+
+type queryBinding struct{}
+
+// Get the name of the query binder.
+func (q queryBinding) Name() string {
+	return "query"
+}
+
+// Bind the query of a request to a given reference to a struct, using the query tags of its fields.
+// Repeated keys, ?tag=a&tag=b, are bound to slices.
+func (q queryBinding) Bind(request *http.Request, obj any) error {
+	if err := q.Decode(request, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+// Decode the request to a given reference to a struct, like Bind, without validating it.
+func (q queryBinding) Decode(request *http.Request, obj any) error {
+	query := request.URL.Query()
+
+	return mapValues(obj, "query", func(key string) ([]string, bool) {
+		vals, ok := query[key]
+		return vals, ok
+	})
+}
@@ -0,0 +1,46 @@
+package binding
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockQuery struct {
+	Key  string   `query:"key" binding:"required"`
+	Tags []string `query:"tag"`
+	Page int      `query:"page" default:"1"`
+}
+
+func TestQueryBindingName(t *testing.T) {
+	binder := queryBinding{}
+	assert.Equal(t, "query", binder.Name())
+}
+
+func TestQueryBindingBind(t *testing.T) {
+	request, err := http.NewRequest("GET", "/test?key=value&tag=a&tag=b", nil)
+	assert.NoError(t, err)
+
+	var obj MockQuery
+	binder := queryBinding{}
+	err = binder.Bind(request, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "value", obj.Key)
+	assert.Equal(t, []string{"a", "b"}, obj.Tags)
+	assert.Equal(t, 1, obj.Page)
+
+	request, err = http.NewRequest("GET", "/test?tag=a", nil)
+	assert.NoError(t, err)
+
+	obj = MockQuery{}
+	err = binder.Bind(request, &obj)
+	assert.Error(t, err)
+
+	request, err = http.NewRequest("GET", "/test?key=value&page=one", nil)
+	assert.NoError(t, err)
+
+	obj = MockQuery{}
+	err = binder.Bind(request, &obj)
+	assert.Error(t, err)
+}
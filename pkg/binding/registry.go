@@ -0,0 +1,64 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Binding is used for binding data in modelling languages.
+package binding
+
+import (
+	"mime"
+	"strings"
+	"sync"
+)
+
+// Binders keyed by the media type they bind, used by Lookup.
+var (
+	binders = map[string]Binder{
+		"application/json":                  JSON,
+		"application/xml":                   XML,
+		"text/xml":                          XML,
+		"application/x-yaml":                YAML,
+		"application/yaml":                  YAML,
+		"text/yaml":                         YAML,
+		"application/toml":                  TOML,
+		"application/x-www-form-urlencoded": FORM,
+		"multipart/form-data":               MULTIPART,
+	}
+
+	bindersMu sync.RWMutex
+)
+
+// Register a Binder for a media type, such as "text/csv", so that it can be found by Lookup.
+// Replaces any Binder already registered for the media type.
+//
+//	binding.Register("application/msgpack", msgpackBinding{})
+func Register(mediaType string, binder Binder) {
+	bindersMu.Lock()
+	defer bindersMu.Unlock()
+
+	binders[strings.ToLower(mediaType)] = binder
+}
+
+// Get the Binder for a content type, such as "application/json; charset=utf-8".
+// If no Binder is registered for the media type, its structured syntax suffix is used,
+// so "application/problem+json" is bound by the "application/json" Binder.
+// Returns false if there is no Binder for the content type.
+func Lookup(contentType string) (Binder, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+
+	bindersMu.RLock()
+	defer bindersMu.RUnlock()
+
+	if binder, ok := binders[mediaType]; ok {
+		return binder, true
+	}
+
+	if i := strings.LastIndex(mediaType, "+"); i >= 0 {
+		binder, ok := binders["application/"+mediaType[i+1:]]
+		return binder, ok
+	}
+
+	return nil, false
+}
@@ -0,0 +1,74 @@
+package binding
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockBinding struct{}
+
+func (mockBinding) Name() string {
+	return "mock"
+}
+
+func (mockBinding) Bind(request *http.Request, obj any) error {
+	return nil
+}
+
+func TestLookup(t *testing.T) {
+	binder, ok := Lookup("application/json; charset=utf-8")
+	assert.True(t, ok)
+	assert.Equal(t, JSON, binder)
+
+	binder, ok = Lookup("Application/XML")
+	assert.True(t, ok)
+	assert.Equal(t, XML, binder)
+
+	binder, ok = Lookup("application/problem+json")
+	assert.True(t, ok)
+	assert.Equal(t, JSON, binder)
+
+	binder, ok = Lookup("application/atom+xml")
+	assert.True(t, ok)
+	assert.Equal(t, XML, binder)
+
+	binder, ok = Lookup("application/x-yaml")
+	assert.True(t, ok)
+	assert.Equal(t, YAML, binder)
+
+	binder, ok = Lookup("application/toml")
+	assert.True(t, ok)
+	assert.Equal(t, TOML, binder)
+
+	binder, ok = Lookup("application/x-www-form-urlencoded")
+	assert.True(t, ok)
+	assert.Equal(t, FORM, binder)
+
+	binder, ok = Lookup("multipart/form-data; boundary=boundary")
+	assert.True(t, ok)
+	assert.Equal(t, MULTIPART, binder)
+
+	_, ok = Lookup("text/csv")
+	assert.False(t, ok)
+
+	_, ok = Lookup("application/vnd+unknown")
+	assert.False(t, ok)
+
+	_, ok = Lookup("")
+	assert.False(t, ok)
+}
+
+func TestRegister(t *testing.T) {
+	Register("Text/CSV", mockBinding{})
+	defer func() {
+		bindersMu.Lock()
+		delete(binders, "text/csv")
+		bindersMu.Unlock()
+	}()
+
+	binder, ok := Lookup("text/csv; charset=utf-8")
+	assert.True(t, ok)
+	assert.Equal(t, "mock", binder.Name())
+}
@@ -12,13 +12,25 @@ import (
 	"github.com/pelletier/go-toml/v2"
 )
 
-type tomlBinding struct{}
+type tomlBinding struct {
+	// Rejects unknown fields, duplicate keys are always rejected.
+	strict bool
+}
 
 func (t tomlBinding) Name() string {
 	return "toml"
 }
 
 func (t tomlBinding) Bind(request *http.Request, obj any) error {
+	if err := t.Decode(request, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+// Decode a request to a given reference to any object, without validating it.
+func (t tomlBinding) Decode(request *http.Request, obj any) error {
 	buff, err := readBody(request)
 	if err != nil {
 		return err
@@ -28,15 +40,23 @@ func (t tomlBinding) Bind(request *http.Request, obj any) error {
 }
 
 func (t tomlBinding) BindBody(body []byte, obj any) error {
-	return t.decodeToml(bytes.NewReader(body), obj)
+	if err := t.decodeToml(bytes.NewReader(body), obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+// Get a strict TOML binder, which rejects unknown fields.
+// The same as StrictTOML.
+func (t tomlBinding) Strict() Decoder {
+	return StrictTOML
 }
 
 func (t tomlBinding) decodeToml(reader io.Reader, obj any) error {
 	decoder := toml.NewDecoder(reader)
-
-	err := decoder.Decode(obj)
-	if err != nil {
-		return err
+	if t.strict {
+		decoder.DisallowUnknownFields()
 	}
 
 	return decoder.Decode(obj)
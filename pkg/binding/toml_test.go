@@ -37,6 +37,14 @@ func TestTOMLBindingBind(t *testing.T) {
 
 	err = binder.Bind(request, &obj)
 	assert.Error(t, err)
+
+	data = []byte(`value = "value"`)
+	request, err = http.NewRequest("GET", "/test/four", bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	obj = Mock{}
+	err = binder.Bind(request, &obj)
+	assert.Error(t, err)
 }
 
 func TestTOMLBindingBindBody(t *testing.T) {
@@ -68,3 +76,23 @@ func TestTOMLBindingBindBody(t *testing.T) {
 	assert.Equal(t, "value", val["key"])
 	assert.Equal(t, "field", val["other"])
 }
+
+func TestTOMLBindingStrict(t *testing.T) {
+	binder := TOML.Strict().(tomlBinding)
+	assert.Equal(t, "toml", binder.Name())
+
+	var obj Mock
+	err := binder.BindBody([]byte(`key = "value"`), &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "value", obj.Key)
+
+	body := []byte("key = \"value\"\nother = \"field\"")
+	err = binder.BindBody(body, &Mock{})
+	assert.Error(t, err)
+
+	err = TOML.BindBody(body, &Mock{})
+	assert.NoError(t, err)
+
+	err = TOML.BindBody([]byte("key = \"value\"\nkey = \"again\""), &Mock{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,34 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Binding is used for binding data in modelling languages.
+package binding
+
+import (
+	"net/http"
+)
+
+type uriBinding struct{}
+
+// Get the name of the uri binder.
+func (u uriBinding) Name() string {
+	return "uri"
+}
+
+// Bind the path parameters of a request to a given reference to a struct, using the uri tags of its fields.
+// The path parameters are those of the pattern of the route, "/users/{id}".
+func (u uriBinding) Bind(request *http.Request, obj any) error {
+	if err := u.Decode(request, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+// Decode the request to a given reference to a struct, like Bind, without validating it.
+func (u uriBinding) Decode(request *http.Request, obj any) error {
+	return mapValues(obj, "uri", func(key string) ([]string, bool) {
+		val := request.PathValue(key)
+		return []string{val}, val != ""
+	})
+}
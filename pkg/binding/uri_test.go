@@ -0,0 +1,38 @@
+package binding
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockURI struct {
+	ID   int    `uri:"id" binding:"required"`
+	Name string `uri:"name"`
+}
+
+func TestURIBindingName(t *testing.T) {
+	binder := uriBinding{}
+	assert.Equal(t, "uri", binder.Name())
+}
+
+func TestURIBindingBind(t *testing.T) {
+	request, err := http.NewRequest("GET", "/test/1", nil)
+	assert.NoError(t, err)
+	request.SetPathValue("id", "1")
+
+	var obj MockURI
+	binder := uriBinding{}
+	err = binder.Bind(request, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, obj.ID)
+	assert.Equal(t, "", obj.Name)
+
+	request, err = http.NewRequest("GET", "/test", nil)
+	assert.NoError(t, err)
+
+	obj = MockURI{}
+	err = binder.Bind(request, &obj)
+	assert.Error(t, err)
+}
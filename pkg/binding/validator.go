@@ -0,0 +1,152 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Binding is used for binding data in modelling languages.
+package binding
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Gives a rule the field being validated, its value with field.Field() and the param of the rule with field.Param().
+type FieldLevel = validator.FieldLevel
+
+// Gives a struct level rule the struct being validated, with level.Current(),
+// errors are reported with level.ReportError.
+type StructLevel = validator.StructLevel
+
+// A custom rule, used with the tag it is registered with, `binding:"rule"`.
+// Returns false if the field is not valid.
+type RuleFunc func(field FieldLevel) bool
+
+// A custom cross field rule, given the value of the field and of the field named by its param,
+// `binding:"rule=Other"`. Returns false if the field is not valid.
+type CrossFieldRuleFunc func(field any, other any) bool
+
+// A struct level rule, used to validate fields that depend on each other.
+type StructRuleFunc func(level StructLevel)
+
+// A StructValidator that can be given custom rules, created with NewValidator.
+// Rules should be registered before the Validator is used, as registering is not safe while validating.
+type RuleValidator interface {
+	StructValidator
+
+	// Register a rule for a tag, `binding:"tag"`.
+	// Fields that are nil or zero are only given to the rule if callEvenIfNull is true.
+	RegisterRule(tag string, fn RuleFunc, callEvenIfNull ...bool) error
+
+	// Register a rule for a tag that compares a field to another field of the same struct,
+	// `binding:"tag=Other"` gives the rule the value of the field Other.
+	RegisterCrossFieldRule(tag string, fn CrossFieldRuleFunc) error
+
+	// Register a struct level rule for the types of the given values.
+	RegisterStructRule(fn StructRuleFunc, types ...any)
+
+	// Register an alias for one or more rules, RegisterAlias("password", "required,min=8").
+	RegisterAlias(alias string, tags string)
+}
+
+// Create a new Validator, that validates structs using their binding tags.
+// Allows each Mux to be given its own rules, with the Validator of its Config.
+//
+//	v := binding.NewValidator()
+//	err := v.RegisterRule("even", func(field binding.FieldLevel) bool {
+//		return field.Field().Int()%2 == 0
+//	})
+func NewValidator() RuleValidator {
+	return &defaultValidator{}
+}
+
+// Register a rule for a tag, `binding:"tag"`, with the Validator of the package.
+// Errors if the Validator does not support custom rules.
+func RegisterRule(tag string, fn RuleFunc, callEvenIfNull ...bool) error {
+	v, err := ruleValidator()
+	if err != nil {
+		return err
+	}
+
+	return v.RegisterRule(tag, fn, callEvenIfNull...)
+}
+
+// Register a cross field rule for a tag, `binding:"tag=Other"`, with the Validator of the package.
+// Errors if the Validator does not support custom rules.
+func RegisterCrossFieldRule(tag string, fn CrossFieldRuleFunc) error {
+	v, err := ruleValidator()
+	if err != nil {
+		return err
+	}
+
+	return v.RegisterCrossFieldRule(tag, fn)
+}
+
+// Register a struct level rule for the types of the given values, with the Validator of the package.
+// Errors if the Validator does not support custom rules.
+func RegisterStructRule(fn StructRuleFunc, types ...any) error {
+	v, err := ruleValidator()
+	if err != nil {
+		return err
+	}
+
+	v.RegisterStructRule(fn, types...)
+	return nil
+}
+
+// Register an alias for one or more rules, with the Validator of the package.
+// Errors if the Validator does not support custom rules.
+func RegisterAlias(alias string, tags string) error {
+	v, err := ruleValidator()
+	if err != nil {
+		return err
+	}
+
+	v.RegisterAlias(alias, tags)
+	return nil
+}
+
+// Gets the Validator of the package, if it supports custom rules.
+func ruleValidator() (RuleValidator, error) {
+	v, ok := Validator.(RuleValidator)
+	if !ok {
+		return nil, errors.New("error, validator does not support custom rules")
+	}
+
+	return v, nil
+}
+
+// Register a rule with the engine of the validator.
+func (v *defaultValidator) RegisterRule(tag string, fn RuleFunc, callEvenIfNull ...bool) error {
+	v.lazyInit()
+	return v.validate.RegisterValidation(tag, func(field validator.FieldLevel) bool {
+		return fn(field)
+	}, callEvenIfNull...)
+}
+
+// Register a cross field rule with the engine of the validator.
+// The field named by the param of the rule is found in the same struct as the field.
+func (v *defaultValidator) RegisterCrossFieldRule(tag string, fn CrossFieldRuleFunc) error {
+	v.lazyInit()
+	return v.validate.RegisterValidation(tag, func(field validator.FieldLevel) bool {
+		other, _, _, ok := field.GetStructFieldOKAdvanced2(field.Parent(), field.Param())
+		if !ok || !other.CanInterface() {
+			return false
+		}
+
+		return fn(field.Field().Interface(), other.Interface())
+	}, true)
+}
+
+// Register a struct level rule with the engine of the validator.
+func (v *defaultValidator) RegisterStructRule(fn StructRuleFunc, types ...any) {
+	v.lazyInit()
+	v.validate.RegisterStructValidation(func(level validator.StructLevel) {
+		fn(level)
+	}, types...)
+}
+
+// Register an alias with the engine of the validator.
+func (v *defaultValidator) RegisterAlias(alias string, tags string) {
+	v.lazyInit()
+	v.validate.RegisterAlias(alias, tags)
+}
@@ -0,0 +1,89 @@
+package binding
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockBooking struct {
+	Guests int       `json:"guests" binding:"even"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end" binding:"after=Start"`
+	Name   string    `json:"name" binding:"name"`
+	Room   string    `json:"room"`
+	Suite  bool      `json:"suite"`
+}
+
+func TestNewValidator(t *testing.T) {
+	v := NewValidator()
+
+	err := v.RegisterRule("even", func(field FieldLevel) bool {
+		return field.Field().Int()%2 == 0
+	})
+	assert.NoError(t, err)
+
+	err = v.RegisterCrossFieldRule("after", func(field any, other any) bool {
+		return field.(time.Time).After(other.(time.Time))
+	})
+	assert.NoError(t, err)
+
+	v.RegisterAlias("name", "required,min=2")
+
+	v.RegisterStructRule(func(level StructLevel) {
+		booking := level.Current().Interface().(MockBooking)
+		if booking.Suite && booking.Room == "" {
+			level.ReportError(booking.Room, "room", "Room", "required_with_suite", "")
+		}
+	}, MockBooking{})
+
+	now := time.Now()
+	err = v.ValidateStruct(MockBooking{Guests: 2, Start: now, End: now.Add(time.Hour), Name: "name"})
+	assert.NoError(t, err)
+
+	err = ValidateWith(v, &MockBooking{Guests: 1, Start: now, End: now, Name: "n", Suite: true})
+	assert.Error(t, err)
+
+	fieldErrs, ok := AsFieldErrors(err)
+	assert.True(t, ok)
+	assert.Equal(t, FieldErrors{
+		{Field: "guests", Rule: "even", Message: `failed on the "even" rule`},
+		{Field: "end", Rule: "after", Param: "Start", Message: `failed on the "after" rule`},
+		{Field: "name", Rule: "name", Param: "2", Message: "must be at least 2 characters long"},
+		{Field: "room", Rule: "required_with_suite", Message: `failed on the "required_with_suite" rule`},
+	}, withoutKind(fieldErrs))
+
+	// rules are not shared with the Validator of the package, which panics on unknown rules.
+	assert.Panics(t, func() {
+		_ = NewValidator().ValidateStruct(MockBooking{})
+	})
+}
+
+func TestRegisterRule(t *testing.T) {
+	previous := Validator
+	Validator = NewValidator()
+	defer func() {
+		Validator = previous
+	}()
+
+	assert.NoError(t, RegisterRule("even", func(field FieldLevel) bool {
+		return field.Field().Int()%2 == 0
+	}))
+	assert.NoError(t, RegisterCrossFieldRule("after", func(field any, other any) bool {
+		return field.(time.Time).After(other.(time.Time))
+	}))
+	assert.NoError(t, RegisterAlias("name", "required"))
+	assert.NoError(t, RegisterStructRule(func(level StructLevel) {}, MockBooking{}))
+
+	now := time.Now()
+	assert.NoError(t, validate(&MockBooking{Guests: 2, Start: now, End: now.Add(time.Hour), Name: "name"}))
+	assert.Error(t, validate(&MockBooking{Guests: 1, Start: now, End: now.Add(time.Hour), Name: "name"}))
+
+	Validator = nil
+	assert.Error(t, RegisterRule("even", func(field FieldLevel) bool { return true }))
+	assert.Error(t, RegisterCrossFieldRule("after", func(field any, other any) bool { return true }))
+	assert.Error(t, RegisterAlias("name", "required"))
+	assert.Error(t, RegisterStructRule(func(level StructLevel) {}, MockBooking{}))
+	assert.NoError(t, validate(&MockBooking{}))
+}
@@ -18,6 +18,15 @@ func (x xmlBinding) Name() string {
 }
 
 func (x xmlBinding) Bind(request *http.Request, obj any) error {
+	if err := x.Decode(request, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+// Decode a request to a given reference to any object, without validating it.
+func (x xmlBinding) Decode(request *http.Request, obj any) error {
 	buff, err := readBody(request)
 	if err != nil {
 		return err
@@ -27,15 +36,14 @@ func (x xmlBinding) Bind(request *http.Request, obj any) error {
 }
 
 func (x xmlBinding) BindBody(body []byte, obj any) error {
-	return x.decodeXML(bytes.NewReader(body), obj)
-}
-func (x xmlBinding) decodeXML(reader io.Reader, obj any) error {
-	decoder := xml.NewDecoder(reader)
-
-	err := decoder.Decode(obj)
-	if err != nil {
+	if err := x.decodeXML(bytes.NewReader(body), obj); err != nil {
 		return err
 	}
 
 	return validate(obj)
 }
+
+func (x xmlBinding) decodeXML(reader io.Reader, obj any) error {
+	decoder := xml.NewDecoder(reader)
+	return decoder.Decode(obj)
+}
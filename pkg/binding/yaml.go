@@ -6,19 +6,32 @@ package binding
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"net/http"
 
 	"gopkg.in/yaml.v3"
 )
 
-type yamlBinding struct{}
+type yamlBinding struct {
+	// Rejects unknown fields and more than one document, duplicate keys are always rejected.
+	strict bool
+}
 
 func (yamlBinding) Name() string {
 	return "yaml"
 }
 
 func (y yamlBinding) Bind(request *http.Request, obj any) error {
+	if err := y.Decode(request, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+// Decode a request to a given reference to any object, without validating it.
+func (y yamlBinding) Decode(request *http.Request, obj any) error {
 	buff, err := readBody(request)
 	if err != nil {
 		return err
@@ -28,16 +41,35 @@ func (y yamlBinding) Bind(request *http.Request, obj any) error {
 }
 
 func (y yamlBinding) BindBody(body []byte, obj any) error {
-	return y.decodeYAML(bytes.NewReader(body), obj)
+	if err := y.decodeYAML(bytes.NewReader(body), obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+// Get a strict YAML binder, which rejects unknown fields and more than one document.
+// The same as StrictYAML.
+func (y yamlBinding) Strict() Decoder {
+	return StrictYAML
 }
 
 func (y yamlBinding) decodeYAML(reader io.Reader, obj any) error {
 	decoder := yaml.NewDecoder(reader)
+	decoder.KnownFields(y.strict)
 
-	err := decoder.Decode(obj)
-	if err != nil {
+	if err := decoder.Decode(obj); err != nil {
 		return err
 	}
 
-	return validate(obj)
+	if !y.strict {
+		return nil
+	}
+
+	var next any
+	if err := decoder.Decode(&next); !errors.Is(err, io.EOF) {
+		return errors.New("error, unexpected data after YAML document")
+	}
+
+	return nil
 }
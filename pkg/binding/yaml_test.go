@@ -54,3 +54,29 @@ func TestYAMLBindingBindBody(t *testing.T) {
 	assert.Len(t, val, 1)
 	assert.Equal(t, "value", val["key"])
 }
+
+func TestYAMLBindingStrict(t *testing.T) {
+	binder := YAML.Strict().(yamlBinding)
+	assert.Equal(t, "yaml", binder.Name())
+
+	var obj Mock
+	err := binder.BindBody([]byte("key: value"), &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "value", obj.Key)
+
+	bodies := []string{
+		"key: value\nother: field",
+		"key: value\n---\nkey: value",
+	}
+
+	for _, body := range bodies {
+		err = binder.BindBody([]byte(body), &Mock{})
+		assert.Error(t, err, body)
+
+		err = YAML.BindBody([]byte(body), &Mock{})
+		assert.NoError(t, err, body)
+	}
+
+	err = YAML.BindBody([]byte("key: value\nkey: again"), &Mock{})
+	assert.Error(t, err)
+}
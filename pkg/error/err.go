@@ -4,7 +4,290 @@
 // Package Error is used for defining structured errors.
 package error
 
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+
+	"github.com/joseph-beck/amp/pkg/status"
+)
+
+// Structured error, with a HTTP status and a message.
+// Can be returned from an amp.Handler, the Mux will respond with the given status and message.
+//
+//	return error.NotFound("user not found").WithCode("user_not_found")
+//
+// Rendered as problem details, RFC 9457, by the Mux.
 type Error struct {
-	Status  int
+	// HTTP status of the error, for example status.NotFound.
+	Status int
+
+	// Human readable message, safe to be given to the client.
+	// If no message is given the text of the status is used instead.
 	Message string
+
+	// Machine readable code for the error, for example "user_not_found".
+	// This field is optional.
+	Code string
+
+	// URI reference identifying the type of problem.
+	// When this is empty, "about:blank" is used.
+	Type string
+
+	// Field level details of the error, for example failed validations.
+	// This field is optional.
+	Details []Detail
+
+	// Headers that will be set on the response, for example Retry-After.
+	// This field is optional.
+	Headers http.Header
+
+	// The underlying error that caused this error.
+	// This is never given to the client, but can be used with errors.Is and errors.As.
+	Cause error
+}
+
+// Field level detail of an Error.
+type Detail struct {
+	// Name or path of the field, for example "user.email".
+	Field string `json:"field" toml:"field" yaml:"field" xml:"field"`
+
+	// Human readable message about the field.
+	Message string `json:"message" toml:"message" yaml:"message" xml:"message"`
+
+	// Machine readable code for the detail, for example "required".
+	Code string `json:"code,omitempty" toml:"code,omitempty" yaml:"code,omitempty" xml:"code,omitempty"`
+}
+
+// Problem details, as defined in RFC 9457, previously RFC 7807.
+// Rendered with the content type application/problem+json.
+type Problem struct {
+	XMLName  xml.Name `json:"-" toml:"-" yaml:"-" xml:"urn:ietf:rfc:7807 problem"`
+	Type     string   `json:"type" toml:"type" yaml:"type" xml:"type"`
+	Title    string   `json:"title" toml:"title" yaml:"title" xml:"title"`
+	Status   int      `json:"status" toml:"status" yaml:"status" xml:"status"`
+	Detail   string   `json:"detail,omitempty" toml:"detail,omitempty" yaml:"detail,omitempty" xml:"detail,omitempty"`
+	Instance string   `json:"instance,omitempty" toml:"instance,omitempty" yaml:"instance,omitempty" xml:"instance,omitempty"`
+	Code     string   `json:"code,omitempty" toml:"code,omitempty" yaml:"code,omitempty" xml:"code,omitempty"`
+	Errors   []Detail `json:"errors,omitempty" toml:"errors,omitempty" yaml:"errors,omitempty" xml:"errors>error,omitempty"`
+}
+
+// Create a new Error with a given status and message.
+func New(status int, message string) *Error {
+	return &Error{
+		Status:  status,
+		Message: message,
+	}
+}
+
+// Wrap an error, with a given status and message.
+// The wrapped error can be retrieved with errors.Unwrap.
+func Wrap(err error, status int, message string) *Error {
+	return &Error{
+		Status:  status,
+		Message: message,
+		Cause:   err,
+	}
+}
+
+// Get an *Error from any error.
+// If the error, or any error it wraps, is an *Error that is returned.
+// A *http.MaxBytesError, given when a body is larger than its limit, is wrapped as a status.PayloadTooLarge.
+// Otherwise the error is wrapped as a status.InternalServerError,
+// the message of the error is not used as it may not be safe to give to a client.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return Wrap(err, status.PayloadTooLarge, "")
+	}
+
+	return Wrap(err, status.InternalServerError, "")
+}
+
+// Get the message of the Error.
+// If no message was given the text of the status is used instead.
+// If the Error has a cause, this is appended to the message.
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.Status)
+	}
+
+	if e.Cause != nil {
+		return msg + ": " + e.Cause.Error()
+	}
+
+	return msg
+}
+
+// Get the cause of the Error, this can be nil.
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Creates a copy of the Error, used by the With methods.
+// Prevents errors declared as variables from being altered.
+func (e *Error) clone() *Error {
+	c := *e
+	c.Details = append([]Detail(nil), e.Details...)
+	c.Headers = e.Headers.Clone()
+	return &c
+}
+
+// Get a copy of the Error with the given code.
+func (e *Error) WithCode(code string) *Error {
+	c := e.clone()
+	c.Code = code
+	return c
+}
+
+// Get a copy of the Error with the given problem type.
+func (e *Error) WithType(typ string) *Error {
+	c := e.clone()
+	c.Type = typ
+	return c
+}
+
+// Get a copy of the Error with a field level detail added.
+func (e *Error) WithDetail(field string, message string, code ...string) *Error {
+	c := e.clone()
+
+	detail := Detail{Field: field, Message: message}
+	if len(code) > 0 {
+		detail.Code = code[0]
+	}
+
+	c.Details = append(c.Details, detail)
+	return c
+}
+
+// Get a copy of the Error with a header that will be set on the response.
+func (e *Error) WithHeader(key string, value string) *Error {
+	c := e.clone()
+	if c.Headers == nil {
+		c.Headers = make(http.Header)
+	}
+
+	c.Headers.Add(key, value)
+	return c
+}
+
+// Get a copy of the Error with the given cause.
+func (e *Error) WithCause(err error) *Error {
+	c := e.clone()
+	c.Cause = err
+	return c
+}
+
+// Get the problem details of the Error.
+// The cause of the Error is never included.
+func (e *Error) Problem() Problem {
+	p := Problem{
+		Type:   e.Type,
+		Title:  http.StatusText(e.Status),
+		Status: e.Status,
+		Detail: e.Message,
+		Code:   e.Code,
+		Errors: e.Details,
+	}
+
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+
+	if p.Title == "" {
+		p.Title = e.Message
+	}
+
+	return p
+}
+
+// Create a new status.BadRequest Error, or a 400.
+func BadRequest(message string) *Error {
+	return New(status.BadRequest, message)
+}
+
+// Create a new status.Unauthorized Error, or a 401.
+func Unauthorized(message string) *Error {
+	return New(status.Unauthorized, message)
+}
+
+// Create a new status.Forbidden Error, or a 403.
+func Forbidden(message string) *Error {
+	return New(status.Forbidden, message)
+}
+
+// Create a new status.NotFound Error, or a 404.
+func NotFound(message string) *Error {
+	return New(status.NotFound, message)
+}
+
+// Create a new status.MethodNotAllowed Error, or a 405.
+func MethodNotAllowed(message string) *Error {
+	return New(status.MethodNotAllowed, message)
+}
+
+// Create a new status.NotAcceptable Error, or a 406.
+func NotAcceptable(message string) *Error {
+	return New(status.NotAcceptable, message)
+}
+
+// Create a new status.RequestTimeout Error, or a 408.
+func RequestTimeout(message string) *Error {
+	return New(status.RequestTimeout, message)
+}
+
+// Create a new status.Conflict Error, or a 409.
+func Conflict(message string) *Error {
+	return New(status.Conflict, message)
+}
+
+// Create a new status.Gone Error, or a 410.
+func Gone(message string) *Error {
+	return New(status.Gone, message)
+}
+
+// Create a new status.PayloadTooLarge Error, or a 413.
+func PayloadTooLarge(message string) *Error {
+	return New(status.PayloadTooLarge, message)
+}
+
+// Create a new status.UnsupportedMediaType Error, or a 415.
+func UnsupportedMediaType(message string) *Error {
+	return New(status.UnsupportedMediaType, message)
+}
+
+// Create a new status.UnprocessableContent Error, or a 422.
+func UnprocessableContent(message string) *Error {
+	return New(status.UnprocessableContent, message)
+}
+
+// Create a new status.TooManyRequests Error, or a 429.
+func TooManyRequests(message string) *Error {
+	return New(status.TooManyRequests, message)
+}
+
+// Create a new status.InternalServerError Error, or a 500.
+func InternalServerError(message string) *Error {
+	return New(status.InternalServerError, message)
+}
+
+// Create a new status.NotImplemented Error, or a 501.
+func NotImplemented(message string) *Error {
+	return New(status.NotImplemented, message)
+}
+
+// Create a new status.ServiceUnavailable Error, or a 503.
+func ServiceUnavailable(message string) *Error {
+	return New(status.ServiceUnavailable, message)
+}
+
+// Create a new status.GatewayTimeout Error, or a 504.
+func GatewayTimeout(message string) *Error {
+	return New(status.GatewayTimeout, message)
 }
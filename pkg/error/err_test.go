@@ -1 +1,112 @@
 package error
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/joseph-beck/amp/pkg/status"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	var err error = &Error{Status: status.NotFound, Message: "user not found"}
+	assert.Equal(t, "user not found", err.Error())
+
+	err = &Error{Status: status.NotFound}
+	assert.Equal(t, "Not Found", err.Error())
+
+	var target *Error
+	assert.True(t, errors.As(err, &target))
+	assert.Equal(t, status.NotFound, target.Status)
+}
+
+func TestNew(t *testing.T) {
+	err := New(status.Conflict, "conflict")
+	assert.Equal(t, status.Conflict, err.Status)
+	assert.Equal(t, "conflict", err.Message)
+	assert.Nil(t, err.Cause)
+}
+
+func TestWrap(t *testing.T) {
+	cause := errors.New("cause")
+	err := Wrap(cause, status.BadGateway, "upstream failed")
+	assert.Equal(t, "upstream failed: cause", err.Error())
+	assert.Equal(t, cause, errors.Unwrap(err))
+	assert.ErrorIs(t, err, cause)
+}
+
+func TestFrom(t *testing.T) {
+	err := NotFound("missing")
+	assert.Equal(t, err, From(err))
+	assert.Equal(t, err, From(errors.Join(errors.New("other"), err)))
+
+	cause := errors.New("secret")
+	e := From(cause)
+	assert.Equal(t, status.InternalServerError, e.Status)
+	assert.Equal(t, "", e.Message)
+	assert.ErrorIs(t, e, cause)
+
+	tooLarge := fmt.Errorf("error, could not read: %w", &http.MaxBytesError{Limit: 8})
+	e = From(tooLarge)
+	assert.Equal(t, status.PayloadTooLarge, e.Status)
+	assert.ErrorIs(t, e, tooLarge)
+}
+
+func TestWith(t *testing.T) {
+	base := NotFound("missing")
+
+	err := base.
+		WithCode("user_not_found").
+		WithType("https://example.com/problems/user-not-found").
+		WithDetail("id", "does not exist", "exists").
+		WithHeader("X-Test", "value").
+		WithCause(errors.New("cause"))
+
+	assert.Equal(t, "user_not_found", err.Code)
+	assert.Equal(t, "https://example.com/problems/user-not-found", err.Type)
+	assert.Equal(t, []Detail{{Field: "id", Message: "does not exist", Code: "exists"}}, err.Details)
+	assert.Equal(t, "value", err.Headers.Get("X-Test"))
+	assert.Error(t, err.Cause)
+
+	// the original error should never be altered.
+	assert.Equal(t, "", base.Code)
+	assert.Equal(t, "", base.Type)
+	assert.Nil(t, base.Details)
+	assert.Nil(t, base.Headers)
+	assert.Nil(t, base.Cause)
+}
+
+func TestProblem(t *testing.T) {
+	p := NotFound("missing").WithCode("code").Problem()
+	assert.Equal(t, "about:blank", p.Type)
+	assert.Equal(t, "Not Found", p.Title)
+	assert.Equal(t, status.NotFound, p.Status)
+	assert.Equal(t, "missing", p.Detail)
+	assert.Equal(t, "code", p.Code)
+
+	p = Wrap(errors.New("secret"), status.InternalServerError, "").Problem()
+	assert.Equal(t, "Internal Server Error", p.Title)
+	assert.Equal(t, "", p.Detail)
+}
+
+func TestConstructors(t *testing.T) {
+	assert.Equal(t, status.BadRequest, BadRequest("").Status)
+	assert.Equal(t, status.Unauthorized, Unauthorized("").Status)
+	assert.Equal(t, status.Forbidden, Forbidden("").Status)
+	assert.Equal(t, status.NotFound, NotFound("").Status)
+	assert.Equal(t, status.MethodNotAllowed, MethodNotAllowed("").Status)
+	assert.Equal(t, status.NotAcceptable, NotAcceptable("").Status)
+	assert.Equal(t, status.RequestTimeout, RequestTimeout("").Status)
+	assert.Equal(t, status.Conflict, Conflict("").Status)
+	assert.Equal(t, status.Gone, Gone("").Status)
+	assert.Equal(t, status.PayloadTooLarge, PayloadTooLarge("").Status)
+	assert.Equal(t, status.UnsupportedMediaType, UnsupportedMediaType("").Status)
+	assert.Equal(t, status.UnprocessableContent, UnprocessableContent("").Status)
+	assert.Equal(t, status.TooManyRequests, TooManyRequests("").Status)
+	assert.Equal(t, status.InternalServerError, InternalServerError("").Status)
+	assert.Equal(t, status.NotImplemented, NotImplemented("").Status)
+	assert.Equal(t, status.ServiceUnavailable, ServiceUnavailable("").Status)
+	assert.Equal(t, status.GatewayTimeout, GatewayTimeout("").Status)
+}
@@ -5,8 +5,6 @@
 package cors
 
 import (
-	"fmt"
-	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
@@ -132,7 +130,7 @@ func New(args ...Config) amp.Handler {
 		ctx.Header("Access-Control-Max-Age", cors.maxAge)
 
 		if cors.debug {
-			slog.Info(fmt.Sprintf("CORS %s %s %s", ctx.Origin(), ctx.Method(), ctx.Path()))
+			ctx.Logger().Info("cors", "origin", ctx.Origin(), "method", ctx.Method(), "path", ctx.Path())
 		}
 
 		return nil
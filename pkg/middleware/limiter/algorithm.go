@@ -0,0 +1,490 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Limiter is a middleware used for rate limiting requests.
+package limiter
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Algorithm used by the rate limiter to count the hits of each key.
+type Algorithm int
+
+const (
+	// Counts the hits of a key in a window of the Duration, starting at its first hit.
+	// Once the window ends the hits are reset, allowing up to twice the Limit across the end of a window.
+	FixedWindow Algorithm = iota
+
+	// Counts the hits of a key in windows of the Duration, weighing the hits of the previous window
+	// by how much it overlaps a window sliding back from now. Smooths the bursts of a FixedWindow.
+	SlidingWindow
+
+	// Keeps the times of the hits of a key, counting those within the Duration before now.
+	// Exact, but keeps the times in memory, and cannot be used with a Storage.
+	SlidingLog
+
+	// Refills a bucket of tokens at a rate of Limit per Duration, up to the Burst, each hit takes a token.
+	// Keeps the buckets in memory, and cannot be used with a Storage.
+	TokenBucket
+)
+
+// Get the name of the Algorithm.
+func (a Algorithm) String() string {
+	switch a {
+	case FixedWindow:
+		return "fixed window"
+	case SlidingWindow:
+		return "sliding window"
+	case SlidingLog:
+		return "sliding log"
+	case TokenBucket:
+		return "token bucket"
+	default:
+		return "Algorithm(" + strconv.Itoa(int(a)) + ")"
+	}
+}
+
+// Checks if the Algorithm keeps its state in the Storage, rather than in memory.
+func (a Algorithm) usesStorage() bool {
+	return a != SlidingLog && a != TokenBucket
+}
+
+// Quota of a key of the rate limiter.
+type Quota struct {
+	// Maximum hits of the key.
+	Limit int
+
+	// Hits remaining before the key is limited.
+	Remaining int
+
+	// Time until the quota of the key resets.
+	// If no hits remain, this is the time until another hit is allowed.
+	Reset time.Duration
+}
+
+// Checks if the Quota has no hits remaining.
+func (q Quota) Limited() bool {
+	return q.Remaining <= 0
+}
+
+// unexported algorithm, counts the hits of keys.
+type algorithm interface {
+	// gets the quota of a key at the time now, without counting a hit.
+	peek(ctx context.Context, key string, now time.Time) (Quota, error)
+
+	// counts n hits of a key at the time now, returning the quota after the hits,
+	// and false if the hits did not fit within the quota.
+	take(ctx context.Context, key string, n int, now time.Time) (Quota, bool, error)
+
+	// refunds n hits of a key taken at the time at, given the quota returned when they were taken,
+	// the time now is when they are refunded.
+	refund(ctx context.Context, key string, n int, at time.Time, taken Quota, now time.Time) error
+}
+
+// unexported purger, removes expired keys kept in memory, used by the janitor.
+type purger interface {
+	// removes the keys that have expired at the time now, returning the number removed.
+	purge(now time.Time) int
+}
+
+// creates the algorithm of the limiter.
+// Algorithms that keep their state in memory evict the least recently used keys once they have the max keys.
+func newAlgorithm(algo Algorithm, storage Storage, limit int, duration time.Duration, burst int, maxKeys int) algorithm {
+	switch algo {
+	case SlidingWindow:
+		return &slidingWindow{storage: storage, limit: limit, duration: duration}
+	case SlidingLog:
+		return &slidingLog{limit: limit, duration: duration, logs: newShards[[]hit](maxKeys)}
+	case TokenBucket:
+		if burst <= 0 {
+			burst = limit
+		}
+
+		return &tokenBucket{limit: limit, duration: duration, burst: burst, buckets: newShards[bucket](maxKeys)}
+	default:
+		return &fixedWindow{storage: storage, limit: limit, duration: duration}
+	}
+}
+
+// fixed window, a counter of the storage that expires after the duration.
+type fixedWindow struct {
+	// unexported storage.
+	storage Storage
+
+	// unexported limit.
+	limit int
+
+	// unexported duration.
+	duration time.Duration
+}
+
+// gets the quota of the window, a key without hits resets after the duration.
+func (a *fixedWindow) peek(ctx context.Context, key string, now time.Time) (Quota, error) {
+	hits, ttl, err := a.storage.Get(ctx, key)
+	if err != nil {
+		return Quota{}, err
+	}
+
+	if hits == 0 {
+		ttl = a.duration
+	}
+
+	return a.quota(hits, ttl), nil
+}
+
+// counts hits in the window, starting a new window if it has expired.
+func (a *fixedWindow) take(ctx context.Context, key string, n int, now time.Time) (Quota, bool, error) {
+	hits, ttl, err := a.storage.Increment(ctx, key, n, a.duration)
+	if err != nil {
+		return Quota{}, false, err
+	}
+
+	return a.quota(hits, ttl), hits <= a.limit, nil
+}
+
+// refunds hits of the window, if the window has since expired there is nothing to refund.
+func (a *fixedWindow) refund(ctx context.Context, key string, n int, at time.Time, taken Quota, now time.Time) error {
+	// the window the hits were taken in resets after the quota, any window after it did not count them.
+	if !now.Before(at.Add(taken.Reset)) {
+		return nil
+	}
+
+	_, _, err := a.storage.Increment(ctx, key, -n, a.duration)
+	return err
+}
+
+// creates the quota of the hits of the window.
+func (a *fixedWindow) quota(hits int, ttl time.Duration) Quota {
+	return Quota{Limit: a.limit, Remaining: max(a.limit-hits, 0), Reset: ttl}
+}
+
+// sliding window, counters of the storage for windows aligned to the duration.
+type slidingWindow struct {
+	// unexported storage.
+	storage Storage
+
+	// unexported limit.
+	limit int
+
+	// unexported duration.
+	duration time.Duration
+}
+
+// gets the quota using the hits of the current and previous windows.
+func (a *slidingWindow) peek(ctx context.Context, key string, now time.Time) (Quota, error) {
+	current, previous, elapsed := a.window(key, now)
+
+	curr, _, err := a.storage.Get(ctx, current)
+	if err != nil {
+		return Quota{}, err
+	}
+
+	prev, _, err := a.storage.Get(ctx, previous)
+	if err != nil {
+		return Quota{}, err
+	}
+
+	return a.quota(prev, curr, elapsed), nil
+}
+
+// counts hits in the current window, which is kept until the end of the next window.
+func (a *slidingWindow) take(ctx context.Context, key string, n int, now time.Time) (Quota, bool, error) {
+	current, previous, elapsed := a.window(key, now)
+
+	curr, _, err := a.storage.Increment(ctx, current, n, 2*a.duration-elapsed)
+	if err != nil {
+		return Quota{}, false, err
+	}
+
+	prev, _, err := a.storage.Get(ctx, previous)
+	if err != nil {
+		return Quota{}, false, err
+	}
+
+	return a.quota(prev, curr, elapsed), math.Floor(a.hits(prev, curr, elapsed)) <= float64(a.limit), nil
+}
+
+// refunds hits of the window they were taken in.
+func (a *slidingWindow) refund(ctx context.Context, key string, n int, at time.Time, taken Quota, now time.Time) error {
+	current, _, elapsed := a.window(key, at)
+
+	_, _, err := a.storage.Increment(ctx, current, -n, 2*a.duration-elapsed-now.Sub(at))
+	return err
+}
+
+// gets the keys of the current and previous windows, and the time elapsed in the current window.
+func (a *slidingWindow) window(key string, now time.Time) (string, string, time.Duration) {
+	index := now.UnixNano() / int64(a.duration)
+	elapsed := time.Duration(now.UnixNano() - index*int64(a.duration))
+
+	return key + ":" + strconv.FormatInt(index, 10), key + ":" + strconv.FormatInt(index-1, 10), elapsed
+}
+
+// gets the hits of the windows, the hits of the previous window are weighed by how much it overlaps.
+func (a *slidingWindow) hits(prev int, curr int, elapsed time.Duration) float64 {
+	weight := float64(a.duration-elapsed) / float64(a.duration)
+	return float64(prev)*weight + float64(curr)
+}
+
+// creates the quota of the hits of the windows.
+func (a *slidingWindow) quota(prev int, curr int, elapsed time.Duration) Quota {
+	remaining := max(a.limit-int(math.Floor(a.hits(prev, curr, elapsed))), 0)
+	if remaining > 0 {
+		return Quota{Limit: a.limit, Remaining: remaining, Reset: a.duration - elapsed}
+	}
+
+	// the weighted hits of the previous window must fall below the limit, within this window or the next.
+	wait := func(prev int, curr int) float64 {
+		return float64(a.duration) * (1 - float64(a.limit-curr)/float64(prev))
+	}
+
+	reset := time.Duration(0)
+	if curr < a.limit {
+		reset = time.Duration(wait(prev, curr)) - elapsed
+	} else {
+		reset = a.duration - elapsed + time.Duration(wait(curr, 0))
+	}
+
+	return Quota{Limit: a.limit, Remaining: 0, Reset: max(reset, 0) + time.Nanosecond}
+}
+
+// sliding log, the times of the hits of each key.
+type slidingLog struct {
+	// unexported limit.
+	limit int
+
+	// unexported duration.
+	duration time.Duration
+
+	// unexported logs, the hits of each key in order of their time.
+	logs *shards[[]hit]
+}
+
+// hits of a sliding log, counted together at a time.
+type hit struct {
+	// time the hits were counted.
+	at time.Time
+
+	// number of hits counted.
+	n int
+}
+
+// gets the quota of the hits of a key within the duration.
+func (a *slidingLog) peek(ctx context.Context, key string, now time.Time) (Quota, error) {
+	var quota Quota
+	a.logs.update(key, now, func(log []hit, ok bool) ([]hit, time.Time) {
+		log = a.prune(log, now)
+		quota = a.quota(log, now)
+		return log, a.expires(log)
+	})
+
+	return quota, nil
+}
+
+// logs hits of a key, together, so that the log does not grow with the number of hits.
+func (a *slidingLog) take(ctx context.Context, key string, n int, now time.Time) (Quota, bool, error) {
+	var quota Quota
+	var allowed bool
+	a.logs.update(key, now, func(log []hit, ok bool) ([]hit, time.Time) {
+		log = a.prune(log, now)
+		if len(log) > 0 && log[len(log)-1].at.Equal(now) {
+			log[len(log)-1].n += n
+		} else {
+			log = append(log, hit{at: now, n: n})
+		}
+
+		quota, allowed = a.quota(log, now), a.hits(log) <= a.limit
+		return log, a.expires(log)
+	})
+
+	return quota, allowed, nil
+}
+
+// removes hits of a key logged at the time at, from the end of the log.
+func (a *slidingLog) refund(ctx context.Context, key string, n int, at time.Time, taken Quota, now time.Time) error {
+	a.logs.update(key, now, func(log []hit, ok bool) ([]hit, time.Time) {
+		log = a.prune(log, now)
+		for i := len(log) - 1; i >= 0; i-- {
+			if !log[i].at.Equal(at) {
+				continue
+			}
+
+			log[i].n -= n
+			if log[i].n <= 0 {
+				log = append(log[:i:i], log[i+1:]...)
+			}
+
+			break
+		}
+
+		return log, a.expires(log)
+	})
+
+	return nil
+}
+
+// removes the hits of a log that are no longer within the duration.
+func (a *slidingLog) prune(log []hit, now time.Time) []hit {
+	i := 0
+	for i < len(log) && !log[i].at.After(now.Add(-a.duration)) {
+		i++
+	}
+
+	if i == len(log) {
+		return nil
+	}
+
+	return log[i:]
+}
+
+// removes the logs that have expired.
+func (a *slidingLog) purge(now time.Time) int {
+	return a.logs.purge(now)
+}
+
+// gets the time a log expires, when its last hit leaves the duration.
+func (a *slidingLog) expires(log []hit) time.Time {
+	if len(log) == 0 {
+		return time.Time{}
+	}
+
+	return log[len(log)-1].at.Add(a.duration)
+}
+
+// gets the number of hits of a log.
+func (a *slidingLog) hits(log []hit) int {
+	hits := 0
+	for _, e := range log {
+		hits += e.n
+	}
+
+	return hits
+}
+
+// creates the quota of a log, it resets when its last hit leaves the duration,
+// or if it is limited, when enough hits leave to allow another.
+func (a *slidingLog) quota(log []hit, now time.Time) Quota {
+	hits := a.hits(log)
+	remaining := max(a.limit-hits, 0)
+	if len(log) == 0 {
+		return Quota{Limit: a.limit, Remaining: remaining, Reset: 0}
+	}
+
+	last := log[len(log)-1]
+	if remaining == 0 {
+		for _, e := range log {
+			hits -= e.n
+			if hits < a.limit {
+				last = e
+				break
+			}
+		}
+	}
+
+	return Quota{Limit: a.limit, Remaining: remaining, Reset: last.at.Add(a.duration).Sub(now)}
+}
+
+// a bucket of tokens of the token bucket.
+type bucket struct {
+	// tokens of the bucket, below 0 if more hits were counted than the tokens it had.
+	tokens float64
+
+	// time the tokens were last refilled.
+	updated time.Time
+}
+
+// token bucket, buckets of tokens for each key.
+type tokenBucket struct {
+	// unexported limit, the tokens refilled each duration.
+	limit int
+
+	// unexported duration.
+	duration time.Duration
+
+	// unexported burst, the maximum tokens of a bucket.
+	burst int
+
+	// unexported buckets of each key.
+	buckets *shards[bucket]
+}
+
+// gets the quota of the tokens of a key.
+func (a *tokenBucket) peek(ctx context.Context, key string, now time.Time) (Quota, error) {
+	var quota Quota
+	a.buckets.update(key, now, func(b bucket, ok bool) (bucket, time.Time) {
+		b = a.refill(b, ok, now)
+		quota = a.quota(b)
+		return b, a.expires(b)
+	})
+
+	return quota, nil
+}
+
+// takes tokens from the bucket of a key.
+func (a *tokenBucket) take(ctx context.Context, key string, n int, now time.Time) (Quota, bool, error) {
+	var quota Quota
+	var allowed bool
+	a.buckets.update(key, now, func(b bucket, ok bool) (bucket, time.Time) {
+		b = a.refill(b, ok, now)
+		b.tokens -= float64(n)
+		quota, allowed = a.quota(b), b.tokens >= 0
+		return b, a.expires(b)
+	})
+
+	return quota, allowed, nil
+}
+
+// returns tokens to the bucket of a key, the bucket is not filled above the burst.
+func (a *tokenBucket) refund(ctx context.Context, key string, n int, at time.Time, taken Quota, now time.Time) error {
+	a.buckets.update(key, now, func(b bucket, ok bool) (bucket, time.Time) {
+		b = a.refill(b, ok, now)
+		b.tokens = min(b.tokens+float64(n), float64(a.burst))
+		return b, a.expires(b)
+	})
+
+	return nil
+}
+
+// refills a bucket with the tokens since it was last refilled, a key without a bucket is given a full bucket.
+func (a *tokenBucket) refill(b bucket, ok bool, now time.Time) bucket {
+	if !ok {
+		return bucket{tokens: float64(a.burst), updated: now}
+	}
+
+	b.tokens = min(b.tokens+float64(now.Sub(b.updated))*a.rate(), float64(a.burst))
+	b.updated = now
+	return b
+}
+
+// removes the buckets that are full.
+func (a *tokenBucket) purge(now time.Time) int {
+	return a.buckets.purge(now)
+}
+
+// gets the time a bucket is full, full buckets expire as they are the same as a key without a bucket.
+func (a *tokenBucket) expires(b bucket) time.Time {
+	return b.updated.Add(time.Duration(math.Ceil((float64(a.burst) - b.tokens) / a.rate())))
+}
+
+// gets the tokens refilled each nanosecond.
+func (a *tokenBucket) rate() float64 {
+	return float64(a.limit) / float64(a.duration)
+}
+
+// creates the quota of a bucket, it resets when the bucket is full,
+// or if it is limited, when it has a token.
+func (a *tokenBucket) quota(b bucket) Quota {
+	remaining := max(int(math.Floor(b.tokens)), 0)
+
+	target := float64(a.burst)
+	if remaining == 0 {
+		target = 1
+	}
+
+	reset := time.Duration(math.Ceil(max(target-b.tokens, 0) / a.rate()))
+	return Quota{Limit: a.burst, Remaining: remaining, Reset: reset}
+}
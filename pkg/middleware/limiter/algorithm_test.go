@@ -0,0 +1,327 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Clock that only moves when it is advanced.
+type mockClock struct {
+	now time.Time
+	mu  sync.Mutex
+}
+
+// Create a mockClock, starting at the start of a minute.
+func newMockClock() *mockClock {
+	return &mockClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// Get the time of the mockClock.
+func (c *mockClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Advance the time of the mockClock.
+func (c *mockClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func TestAlgorithmString(t *testing.T) {
+	assert.Equal(t, "fixed window", FixedWindow.String())
+	assert.Equal(t, "sliding window", SlidingWindow.String())
+	assert.Equal(t, "sliding log", SlidingLog.String())
+	assert.Equal(t, "token bucket", TokenBucket.String())
+	assert.Equal(t, "Algorithm(9)", Algorithm(9).String())
+}
+
+func TestQuotaLimited(t *testing.T) {
+	assert.False(t, Quota{Limit: 1, Remaining: 1}.Limited())
+	assert.True(t, Quota{Limit: 1, Remaining: 0}.Limited())
+}
+
+func TestFixedWindow(t *testing.T) {
+	clock := newMockClock()
+	store := newStore()
+	store.clock = clock.Now
+
+	algo := newAlgorithm(FixedWindow, store, 2, time.Minute, 0, 0)
+	ctx := context.Background()
+
+	quota, err := algo.peek(ctx, "key", clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 2, Remaining: 2, Reset: time.Minute}, quota)
+
+	quota, _, err = algo.take(ctx, "key", 1, clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 2, Remaining: 1, Reset: time.Minute}, quota)
+
+	clock.Advance(30 * time.Second)
+
+	quota, _, err = algo.take(ctx, "key", 1, clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 2, Remaining: 0, Reset: 30 * time.Second}, quota)
+
+	quota, err = algo.peek(ctx, "key", clock.Now())
+	assert.NoError(t, err)
+	assert.True(t, quota.Limited())
+
+	// the window resets once it ends, and a new window starts at the next hit.
+	clock.Advance(30 * time.Second)
+
+	quota, err = algo.peek(ctx, "key", clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 2, Remaining: 2, Reset: time.Minute}, quota)
+
+	clock.Advance(10 * time.Second)
+
+	quota, _, err = algo.take(ctx, "key", 1, clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 2, Remaining: 1, Reset: time.Minute}, quota)
+}
+
+func TestSlidingWindow(t *testing.T) {
+	clock := newMockClock()
+	store := newStore()
+	store.clock = clock.Now
+
+	algo := newAlgorithm(SlidingWindow, store, 4, time.Minute, 0, 0)
+	ctx := context.Background()
+
+	quota, err := algo.peek(ctx, "key", clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 4, Remaining: 4, Reset: time.Minute}, quota)
+
+	for range 4 {
+		quota, _, err = algo.take(ctx, "key", 1, clock.Now())
+		assert.NoError(t, err)
+	}
+
+	// the hits of this window still count at the start of the next window.
+	assert.Equal(t, Quota{Limit: 4, Remaining: 0, Reset: time.Minute + time.Nanosecond}, quota)
+
+	clock.Advance(time.Minute)
+
+	quota, err = algo.peek(ctx, "key", clock.Now())
+	assert.NoError(t, err)
+	assert.True(t, quota.Limited())
+
+	// a quarter of the way through the window, three quarters of the previous window are counted.
+	clock.Advance(15 * time.Second)
+
+	quota, err = algo.peek(ctx, "key", clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 4, Remaining: 1, Reset: 45 * time.Second}, quota)
+
+	quota, _, err = algo.take(ctx, "key", 1, clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 4, Remaining: 0, Reset: time.Nanosecond}, quota)
+
+	clock.Advance(15 * time.Second)
+
+	quota, err = algo.peek(ctx, "key", clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 4, Remaining: 1, Reset: 30 * time.Second}, quota)
+
+	// after two windows without hits, the key is no longer counted.
+	clock.Advance(2 * time.Minute)
+
+	quota, err = algo.peek(ctx, "key", clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 4, quota.Remaining)
+}
+
+func TestSlidingLog(t *testing.T) {
+	clock := newMockClock()
+	algo := newAlgorithm(SlidingLog, nil, 2, time.Minute, 0, 0)
+	ctx := context.Background()
+
+	quota, _, err := algo.take(ctx, "key", 1, clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 2, Remaining: 1, Reset: time.Minute}, quota)
+
+	clock.Advance(20 * time.Second)
+
+	quota, _, err = algo.take(ctx, "key", 1, clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 2, Remaining: 0, Reset: 40 * time.Second}, quota)
+
+	quota, err = algo.peek(ctx, "key", clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 2, Remaining: 0, Reset: 40 * time.Second}, quota)
+
+	// the first hit leaves the log once it is a Duration old.
+	clock.Advance(40 * time.Second)
+
+	quota, err = algo.peek(ctx, "key", clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 2, Remaining: 1, Reset: 20 * time.Second}, quota)
+
+	clock.Advance(20 * time.Second)
+
+	quota, err = algo.peek(ctx, "key", clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 2, Remaining: 2, Reset: 0}, quota)
+	assert.Equal(t, 0, algo.(*slidingLog).logs.len())
+}
+
+func TestTokenBucket(t *testing.T) {
+	clock := newMockClock()
+	algo := newAlgorithm(TokenBucket, nil, 2, time.Minute, 4, 0)
+	ctx := context.Background()
+
+	quota, err := algo.peek(ctx, "key", clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 4, Remaining: 4, Reset: 0}, quota)
+
+	// the burst allows more hits than the rate at once.
+	for range 4 {
+		quota, _, err = algo.take(ctx, "key", 1, clock.Now())
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 0, quota.Remaining)
+	assert.InDelta(t, 30*time.Second, quota.Reset, float64(time.Millisecond))
+
+	clock.Advance(15 * time.Second)
+
+	quota, err = algo.peek(ctx, "key", clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, quota.Remaining)
+	assert.InDelta(t, 15*time.Second, quota.Reset, float64(time.Millisecond))
+
+	// tokens refill at a rate of Limit per Duration, until the bucket is full.
+	clock.Advance(15 * time.Second)
+
+	quota, err = algo.peek(ctx, "key", clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, quota.Remaining)
+	assert.InDelta(t, 90*time.Second, quota.Reset, float64(time.Millisecond))
+
+	clock.Advance(2 * time.Minute)
+
+	quota, err = algo.peek(ctx, "key", clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 4, Remaining: 4, Reset: 0}, quota)
+	assert.Equal(t, 0, algo.(*tokenBucket).buckets.len())
+
+	// the burst defaults to the limit.
+	algo = newAlgorithm(TokenBucket, nil, 2, time.Minute, 0, 0)
+	quota, err = algo.peek(ctx, "key", clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 2, Remaining: 2, Reset: 0}, quota)
+}
+
+func TestAlgorithmRefund(t *testing.T) {
+	clock := newMockClock()
+	store := newStore()
+	store.clock = clock.Now
+	ctx := context.Background()
+
+	algos := map[Algorithm]algorithm{
+		FixedWindow:   newAlgorithm(FixedWindow, store, 3, time.Minute, 0, 0),
+		SlidingWindow: newAlgorithm(SlidingWindow, store, 3, time.Minute, 0, 0),
+		SlidingLog:    newAlgorithm(SlidingLog, nil, 3, time.Minute, 0, 0),
+		TokenBucket:   newAlgorithm(TokenBucket, nil, 3, time.Minute, 0, 0),
+	}
+
+	for algo, a := range algos {
+		key := algo.String()
+		at := clock.Now()
+
+		taken, allowed, err := a.take(ctx, key, 2, at)
+		assert.NoError(t, err, algo)
+		assert.True(t, allowed, algo)
+		assert.Equal(t, 1, taken.Remaining, algo)
+
+		// hits that do not fit are still taken, until they are refunded.
+		quota, allowed, err := a.take(ctx, key, 2, at)
+		assert.NoError(t, err, algo)
+		assert.False(t, allowed, algo)
+		assert.Equal(t, 0, quota.Remaining, algo)
+
+		err = a.refund(ctx, key, 2, at, quota, at)
+		assert.NoError(t, err, algo)
+
+		quota, err = a.peek(ctx, key, at)
+		assert.NoError(t, err, algo)
+		assert.Equal(t, 1, quota.Remaining, algo)
+
+		// hits are refunded from when they were taken.
+		clock.Advance(10 * time.Second)
+
+		err = a.refund(ctx, key, 2, at, taken, clock.Now())
+		assert.NoError(t, err, algo)
+
+		quota, err = a.peek(ctx, key, clock.Now())
+		assert.NoError(t, err, algo)
+		assert.Equal(t, 3, quota.Remaining, algo)
+	}
+}
+
+func TestFixedWindowRefundExpired(t *testing.T) {
+	clock := newMockClock()
+	store := newStore()
+	store.clock = clock.Now
+	algo := newAlgorithm(FixedWindow, store, 3, time.Minute, 0, 0)
+	ctx := context.Background()
+
+	at := clock.Now()
+	taken, _, err := algo.take(ctx, "key", 2, at)
+	assert.NoError(t, err)
+
+	// the window the hits were taken in has expired, so the hits of the next window are kept.
+	clock.Advance(time.Minute + time.Second)
+
+	_, _, err = algo.take(ctx, "key", 2, clock.Now())
+	assert.NoError(t, err)
+
+	err = algo.refund(ctx, "key", 2, at, taken, clock.Now())
+	assert.NoError(t, err)
+
+	quota, err := algo.peek(ctx, "key", clock.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, quota.Remaining)
+}
+
+func TestSlidingLogCost(t *testing.T) {
+	clock := newMockClock()
+	algo := newAlgorithm(SlidingLog, nil, 2, time.Minute, 0, 0)
+	ctx := context.Background()
+
+	// hits taken together are logged together, however many there are.
+	at := clock.Now()
+	quota, allowed, err := algo.take(ctx, "key", 1000000, at)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 0, quota.Remaining)
+	assert.Equal(t, time.Minute, quota.Reset)
+
+	algo.(*slidingLog).logs.update("key", at, func(log []hit, ok bool) ([]hit, time.Time) {
+		assert.Len(t, log, 1)
+		return log, log[0].at.Add(time.Minute)
+	})
+
+	err = algo.refund(ctx, "key", 1000000, at, quota, at)
+	assert.NoError(t, err)
+
+	clock.Advance(10 * time.Second)
+
+	_, allowed, err = algo.take(ctx, "key", 1, clock.Now())
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	quota, allowed, err = algo.take(ctx, "key", 1, clock.Now())
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, Quota{Limit: 2, Remaining: 0, Reset: time.Minute}, quota)
+}
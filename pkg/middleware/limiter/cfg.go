@@ -23,12 +23,13 @@ type Config struct {
 	NextFunc amp.Handler
 
 	// Allows you to generate custom keys based on the value returned from this function.
-	// The default key generator will use the host address of the request.
+	// The default key generator will use the IP address of the client, ctx.ClientIP(),
+	// configure the TrustedProxies of the Mux when behind a proxy.
 	// When using the Default(), KeyGeneratorFunc will be nil.
 	KeyGeneratorFunc func(ctx *amp.Ctx) string
 
 	// Maximum number of tries that can be done before being rate limited.
-	// When the user is rate limited they will be sent a status.Locked or a 423.
+	// When the user is rate limited they will be sent a status.TooManyRequests or a 429.
 	// When using the Default(), Limit will be 10.
 	Limit int
 
@@ -36,8 +37,19 @@ type Config struct {
 	// When using the Default(), Duration will be 1 * time.Minute.
 	Duration time.Duration
 
+	// Algorithm used to count the hits of each key, such as a SlidingWindow or a TokenBucket.
+	// The SlidingLog and TokenBucket keep their state in memory, and cannot be used with a Storage.
+	// When using the Default(), Algorithm will be FixedWindow.
+	Algorithm Algorithm
+
+	// Maximum tokens of the buckets of the TokenBucket, allowing bursts of hits above the rate of Limit per Duration.
+	// If this is 0, the Limit is used.
+	// When using the Default(), Burst will be 0.
+	Burst int
+
 	// Define the error code of the rate limit.
-	// When using Default(), LimitCode will be status.Locked or 423.
+	// If this is 0, status.TooManyRequests is used.
+	// When using Default(), LimitCode will be status.TooManyRequests or 429.
 	LimitCode int
 
 	// If the request has a code that is >= 400, then it will not be counted towards the rate limiter.
@@ -48,6 +60,46 @@ type Config struct {
 	// When using the Default(), SkipSuccess is false.
 	SkipSuccess bool
 
+	// Counts the hits of a request before running the next handlers, reserving them from the quota,
+	// so that a burst of concurrent requests cannot all pass the rate limit before any is counted.
+	// Hits are refunded once the request is done, if it is skipped by SkipFails or SkipSuccess.
+	// Otherwise hits are counted once the request is done.
+	// When using the Default(), Reserve is false.
+	Reserve bool
+
+	// Gives the cost of a request, the hits it takes from the quota, allowing expensive requests to cost more.
+	// A request that costs more than the Limit is always rate limited, one that costs 0 or less is not counted.
+	// When using the Default(), CostFunc will be nil, and each request costs 1.
+	CostFunc func(ctx *amp.Ctx) int
+
+	// Stores the hits of each key, share a Storage between replicas of a service to enforce one limit across them,
+	// such as a RESPStorage connected to Redis. Only the FixedWindow and SlidingWindow use the Storage,
+	// New() panics if this is given with another Algorithm.
+	// When using the Default(), Storage will be nil, and an in memory Storage is created by New().
+	Storage Storage
+
+	// Maximum number of keys kept in memory, once reached the least recently used keys are evicted,
+	// forgetting their hits. Applies to the in memory Storage created by New(), and to the SlidingLog and TokenBucket.
+	// If this is 0, there is no maximum.
+	// When using the Default(), MaxKeys will be 100000.
+	MaxKeys int
+
+	// Interval the janitor removes the expired keys kept in memory.
+	// The janitor is started by the first request, and stopped when the Mux is shutdown.
+	// If this is 0, the Duration is used, if this is negative, there is no janitor.
+	// When using the Default(), JanitorInterval will be 1 * time.Minute.
+	JanitorInterval time.Duration
+
+	// Gives the current time, replace this to control time when testing the rate limiter.
+	// When using the Default(), Clock will be nil, and time.Now is used.
+	Clock func() time.Time
+
+	// Stops the rate limit headers being set on every response, RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset,
+	// and the legacy X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset.
+	// Retry-After is still set on responses that are rate limited.
+	// When using the Default(), DisableHeaders is false.
+	DisableHeaders bool
+
 	// Allows us to debug our rate limitting, will print information such as the time since request, key, etc.
 	// When using Default(), Debug is false.
 	Debug bool
@@ -56,13 +108,22 @@ type Config struct {
 // Returns the default configuration for the rate limiter.
 func Default() Config {
 	return Config{
-		SkipFunc:    nil,
-		NextFunc:    nil,
-		Limit:       10,
-		Duration:    1 * time.Minute,
-		LimitCode:   status.Locked,
-		SkipFails:   false,
-		SkipSuccess: false,
-		Debug:       false,
+		SkipFunc:        nil,
+		NextFunc:        nil,
+		Limit:           10,
+		Duration:        1 * time.Minute,
+		Algorithm:       FixedWindow,
+		Burst:           0,
+		LimitCode:       status.TooManyRequests,
+		SkipFails:       false,
+		SkipSuccess:     false,
+		Reserve:         false,
+		CostFunc:        nil,
+		Storage:         nil,
+		MaxKeys:         100000,
+		JanitorInterval: 1 * time.Minute,
+		Clock:           nil,
+		DisableHeaders:  false,
+		Debug:           false,
 	}
 }
@@ -0,0 +1,80 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Limiter is a middleware used for rate limiting requests.
+package limiter
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/joseph-beck/amp/pkg/amp"
+)
+
+// Key of the Quota of the request in the values of the Ctx, use GetQuota to get it.
+const QuotaKey = "limiter.quota"
+
+// headers of the rate limiter, those of the IETF draft and the legacy X-RateLimit headers.
+const (
+	headerLimit           = "RateLimit-Limit"
+	headerRemaining       = "RateLimit-Remaining"
+	headerReset           = "RateLimit-Reset"
+	headerLegacyLimit     = "X-RateLimit-Limit"
+	headerLegacyRemaining = "X-RateLimit-Remaining"
+	headerLegacyReset     = "X-RateLimit-Reset"
+	headerRetryAfter      = "Retry-After"
+)
+
+// Get the Quota of the request, set by the rate limiter before the next handlers are ran.
+// Returns false if the request has not been through a rate limiter.
+//
+//	quota, ok := limiter.GetQuota(ctx)
+//	if ok && quota.Remaining < 10 {
+//		ctx.Logger().Warn("client is close to its rate limit")
+//	}
+func GetQuota(ctx *amp.Ctx) (Quota, bool) {
+	val, err := ctx.Get(QuotaKey)
+	if err != nil {
+		return Quota{}, false
+	}
+
+	quota, ok := val.(Quota)
+	return quota, ok
+}
+
+// sets the rate limit headers of the response, from a quota at the time now.
+// RateLimit-Reset is the seconds until the quota resets, X-RateLimit-Reset is the unix time it resets.
+func setHeaders(ctx *amp.Ctx, quota Quota, now time.Time) {
+	header := ctx.Writer().Header()
+
+	limit := strconv.Itoa(quota.Limit)
+	remaining := strconv.Itoa(quota.Remaining)
+	reset := seconds(quota.Reset)
+
+	header.Set(headerLimit, limit)
+	header.Set(headerRemaining, remaining)
+	header.Set(headerReset, strconv.Itoa(reset))
+	header.Set(headerLegacyLimit, limit)
+	header.Set(headerLegacyRemaining, remaining)
+	header.Set(headerLegacyReset, strconv.FormatInt(unix(now.Add(quota.Reset)), 10))
+}
+
+// sets the Retry-After header of a rejected response, the seconds until another hit is allowed.
+func setRetryAfter(ctx *amp.Ctx, quota Quota) {
+	ctx.Writer().Header().Set(headerRetryAfter, strconv.Itoa(seconds(quota.Reset)))
+}
+
+// gets the unix time of a time, rounded up to the next second.
+func unix(t time.Time) int64 {
+	if t.Nanosecond() > 0 {
+		return t.Unix() + 1
+	}
+
+	return t.Unix()
+}
+
+// gets the whole seconds of a duration, rounded up so that clients do not retry too early.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,21 @@
+package limiter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeconds(t *testing.T) {
+	assert.Equal(t, 0, seconds(0))
+	assert.Equal(t, 1, seconds(time.Nanosecond))
+	assert.Equal(t, 1, seconds(time.Second))
+	assert.Equal(t, 2, seconds(1500*time.Millisecond))
+}
+
+func TestUnix(t *testing.T) {
+	now := time.Unix(100, 0)
+	assert.Equal(t, int64(100), unix(now))
+	assert.Equal(t, int64(101), unix(now.Add(time.Nanosecond)))
+}
@@ -0,0 +1,53 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Limiter is a middleware used for rate limiting requests.
+package limiter
+
+import (
+	"sync"
+	"time"
+)
+
+// starts the janitor, a goroutine that removes the expired keys kept in memory every janitor interval.
+// Returns a function that stops the janitor, or nil if no keys are kept in memory.
+func (l *limiter) startJanitor() func() {
+	purgers := make([]purger, 0, 2)
+	if p, ok := l.storage.(purger); ok {
+		purgers = append(purgers, p)
+	}
+
+	if p, ok := l.algorithm.(purger); ok {
+		purgers = append(purgers, p)
+	}
+
+	if len(purgers) == 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(l.janitorInterval)
+	done := make(chan struct{})
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				now := l.clock()
+				for _, p := range purgers {
+					p.purge(now)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiterStartJanitor(t *testing.T) {
+	clock := newMockClock()
+	memory := newStore()
+	memory.clock = clock.Now
+
+	l := &limiter{
+		storage:         memory,
+		algorithm:       newAlgorithm(SlidingLog, memory, 1, time.Minute, 0, 0),
+		clock:           clock.Now,
+		janitorInterval: time.Millisecond,
+	}
+
+	_, _, err := memory.Increment(context.Background(), "1", 1, time.Minute)
+	assert.NoError(t, err)
+	_, _, err = l.algorithm.take(context.Background(), "1", 1, clock.Now())
+	assert.NoError(t, err)
+
+	stop := l.startJanitor()
+	assert.NotNil(t, stop)
+	defer stop()
+
+	// keys are only removed once they expire.
+	time.Sleep(5 * time.Millisecond)
+	assert.True(t, memory.exists("1"))
+
+	clock.Advance(time.Minute)
+	assert.Eventually(t, func() bool {
+		return !memory.exists("1") && l.algorithm.(*slidingLog).logs.len() == 0
+	}, time.Second, time.Millisecond)
+
+	// stopping more than once is allowed.
+	stop()
+	stop()
+
+	// there is no janitor without keys kept in memory.
+	l = &limiter{
+		storage:         NewRESPStorage(RESPConfig{Addr: "127.0.0.1:0"}),
+		algorithm:       newAlgorithm(FixedWindow, nil, 1, time.Minute, 0, 0),
+		clock:           clock.Now,
+		janitorInterval: time.Millisecond,
+	}
+	assert.Nil(t, l.startJanitor())
+}
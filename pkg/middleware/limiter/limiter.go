@@ -5,8 +5,9 @@
 package limiter
 
 import (
+	"errors"
 	"fmt"
-	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/joseph-beck/amp/pkg/amp"
@@ -22,7 +23,7 @@ type limiter struct {
 	nextFunc amp.Handler
 
 	// unexported keyGeneratorFunc.
-	// if we are not given a key generator, a default one using the origin is created.
+	// if we are not given a key generator, a default one using the client ip is created.
 	keyGeneratorFunc func(ctx *amp.Ctx) string
 
 	// unexported limit.
@@ -34,7 +35,7 @@ type limiter struct {
 	duration time.Duration
 
 	// unexported limitCode.
-	// this will default to 423 if it is set to 0.
+	// this will default to 429 if it is set to 0.
 	limitCode int
 
 	// unexported skipFails.
@@ -43,16 +44,44 @@ type limiter struct {
 	// unexported skipSuccess.
 	skipSuccess bool
 
+	// unexported reserve.
+	// if true, hits are counted before running the chain, and refunded if they are skipped.
+	reserve bool
+
+	// unexported costFunc.
+	// if we are not given a cost func, each request costs 1.
+	costFunc func(ctx *amp.Ctx) int
+
 	// unexported debug.
 	debug bool
 
-	// unexported store.
-	// stores all requests and their corresponding hits and time since requests.
-	store store
+	// unexported disableHeaders.
+	disableHeaders bool
+
+	// unexported storage.
+	// stores the hits of each key, and when they expire.
+	storage Storage
+
+	// unexported algorithm.
+	// counts the hits of each key, using the storage.
+	algorithm algorithm
+
+	// unexported clock.
+	// if we are not given a clock, time.Now is used.
+	clock func() time.Time
+
+	// unexported janitorInterval.
+	// if the interval is 0 or less, there is no janitor.
+	janitorInterval time.Duration
+
+	// unexported janitorOnce.
+	// starts the janitor on the first request.
+	janitorOnce sync.Once
 }
 
 // Create a new rate limiter middleware.
 // If this is given a config it will use that, otherwise Default() config is used.
+// Panics if given a Storage with the SlidingLog or TokenBucket, as they keep their state in memory.
 func New(args ...Config) amp.Handler {
 	cfg := Default()
 
@@ -60,11 +89,32 @@ func New(args ...Config) amp.Handler {
 		cfg = args[0]
 	}
 
+	// the limit would not be shared through the storage, so lets not accept one.
+	if cfg.Storage != nil && !cfg.Algorithm.usesStorage() {
+		panic(fmt.Sprintf("error, limiter: the %s algorithm does not use the Storage", cfg.Algorithm))
+	}
+
 	limiter := limiter{
-		skipFails:   cfg.SkipFails,
-		skipSuccess: cfg.SkipSuccess,
-		debug:       cfg.Debug,
-		store:       newStore(),
+		skipFails:      cfg.SkipFails,
+		skipSuccess:    cfg.SkipSuccess,
+		reserve:        cfg.Reserve,
+		costFunc:       cfg.CostFunc,
+		debug:          cfg.Debug,
+		disableHeaders: cfg.DisableHeaders,
+		storage:        cfg.Storage,
+		clock:          cfg.Clock,
+	}
+
+	// lets use the current time if we are not given a clock.
+	if limiter.clock == nil {
+		limiter.clock = time.Now
+	}
+
+	// lets use an in memory storage if we are not given one, using our clock.
+	if limiter.storage == nil {
+		memory := newStoreWithMax(cfg.MaxKeys)
+		memory.clock = limiter.clock
+		limiter.storage = memory
 	}
 
 	// lets set skip if we have a skip func.
@@ -77,12 +127,12 @@ func New(args ...Config) amp.Handler {
 		limiter.nextFunc = cfg.NextFunc
 	}
 
-	// default key generator uses the origin as a key.
+	// default key generator uses the ip address of the client as a key.
 	if cfg.KeyGeneratorFunc != nil {
 		limiter.keyGeneratorFunc = cfg.KeyGeneratorFunc
 	} else {
 		limiter.keyGeneratorFunc = func(ctx *amp.Ctx) string {
-			return ctx.Origin()
+			return ctx.ClientIP()
 		}
 	}
 
@@ -102,11 +152,21 @@ func New(args ...Config) amp.Handler {
 	}
 	limiter.duration = cfg.Duration
 
+	// create the algorithm now that we have our limit and duration.
+	limiter.algorithm = newAlgorithm(cfg.Algorithm, limiter.storage, limiter.limit, limiter.duration, cfg.Burst, cfg.MaxKeys)
+
+	// the janitor uses the duration if we are not given an interval.
+	if cfg.JanitorInterval == 0 {
+		limiter.janitorInterval = limiter.duration
+	} else {
+		limiter.janitorInterval = cfg.JanitorInterval
+	}
+
 	// check to see if the limit code is valid otherwise set it do default.
 	if cfg.LimitCode > 0 {
 		limiter.limitCode = cfg.LimitCode
 	} else {
-		limiter.limitCode = status.Locked
+		limiter.limitCode = status.TooManyRequests
 	}
 
 	return func(ctx *amp.Ctx) error {
@@ -117,59 +177,138 @@ func New(args ...Config) amp.Handler {
 			}
 		}
 
-		// get our key, if we have a key generator use that, otherwise we get it from the origin.
+		// start the janitor on the first request, so that it is stopped when the Mux is shutdown.
+		if limiter.janitorInterval > 0 {
+			limiter.janitorOnce.Do(func() {
+				if stop := limiter.startJanitor(); stop != nil {
+					ctx.OnShutdown(stop)
+				}
+			})
+		}
+
+		// get our key, if we have a key generator use that, otherwise we get it from the client ip.
 		key := func() string {
 			if limiter.keyGeneratorFunc != nil {
 				return limiter.keyGeneratorFunc(ctx)
 			}
 
-			return ctx.Origin()
+			return ctx.ClientIP()
 		}()
 
-		// is our current request rate limited?
-		limited := limiter.store.limited(key, limiter.duration, limiter.limit)
+		// get the cost of our request, requests that cost nothing are not counted.
+		cost := 1
+		if limiter.costFunc != nil {
+			cost = limiter.costFunc(ctx)
+		}
+
+		if cost <= 0 {
+			return nil
+		}
 
-		// if we are rate limited abort.
-		if limited {
-			ctx.Abort()
+		now := limiter.clock()
+		var quota Quota
+
+		if limiter.reserve {
+			// reserve our cost before running the chain, so that concurrent requests cannot all pass the check.
+			reserved, allowed, err := limiter.algorithm.take(ctx, key, cost, now)
+			if err != nil {
+				return err
+			}
 
-			// if we have a custom next function, use it.
-			if limiter.nextFunc != nil {
-				err := limiter.nextFunc(ctx)
+			// if we are rate limited, give back what we reserved and abort.
+			if !allowed {
+				err := limiter.algorithm.refund(ctx, key, cost, now, reserved, now)
 				if err != nil {
 					return err
 				}
 
-				return nil
+				quota, err := limiter.algorithm.peek(ctx, key, now)
+				if err != nil {
+					return err
+				}
+
+				return limiter.limited(ctx, quota, now)
+			}
+
+			quota = reserved
+		} else {
+			// is our current request rate limited?
+			peeked, err := limiter.algorithm.peek(ctx, key, now)
+			if err != nil {
+				return err
 			}
 
-			// render rate limited msg, with our limit code and exit.
-			return ctx.Render(limiter.limitCode, "Rate Limit Reached")
+			// if we are rate limited abort.
+			if peeked.Remaining < cost {
+				return limiter.limited(ctx, peeked, now)
+			}
+
+			// this request will use some of the remaining hits, let the handlers and client know.
+			peeked.Remaining -= cost
+			quota = peeked
+		}
+
+		ctx.Set(QuotaKey, quota)
+
+		if !limiter.disableHeaders {
+			setHeaders(ctx, quota, now)
 		}
 
 		// iterate through our stack
 		err := ctx.Next()
-		if err != nil {
-			return err
-		}
 
-		// iterate limiter if we are not skipping fails and the request has failed.
-		if !limiter.skipFails && ctx.GetStatus() >= 400 {
-			limiter.store.insert(key)
-		}
+		// we do not count fails if we are skipping them, nor successes if we are skipping those.
+		failed := err != nil || ctx.GetStatus() >= 400
+		skipped := (failed && limiter.skipFails) || (!failed && limiter.skipSuccess)
+
+		switch {
+		case limiter.reserve && skipped:
+			// refund what we reserved, as this request is not counted.
+			refundErr := limiter.algorithm.refund(ctx, key, cost, now, quota, limiter.clock())
+			if refundErr != nil {
+				return errors.Join(err, refundErr)
+			}
+
+		case !limiter.reserve && !skipped:
+			taken, _, takeErr := limiter.algorithm.take(ctx, key, cost, limiter.clock())
+			if takeErr != nil {
+				return errors.Join(err, takeErr)
+			}
 
-		// iterate limiter if we are not skipping success and the request has succeeded.
-		if !limiter.skipSuccess && ctx.GetStatus() < 400 {
-			limiter.store.insert(key)
+			quota = taken
+			ctx.Set(QuotaKey, quota)
 		}
 
 		// give some info if we are using the debugger.
 		if limiter.debug {
-			item := limiter.store.get(key)
-			slog.Info(fmt.Sprintf("LIMITER %s %v %d", key, item.timeSinceRequest, item.hits))
+			ctx.Logger().Info("limiter", "key", key, "cost", cost, "counted", !skipped, "remaining", quota.Remaining, "reset", quota.Reset)
 		}
 
 		// if we are not rate limited lets just continue through the mux.
+		return err
+	}
+}
+
+// rejects a request that is rate limited, telling the client when to retry.
+func (l *limiter) limited(ctx *amp.Ctx, quota Quota, now time.Time) error {
+	ctx.Abort()
+	ctx.Set(QuotaKey, quota)
+
+	if !l.disableHeaders {
+		setHeaders(ctx, quota, now)
+	}
+	setRetryAfter(ctx, quota)
+
+	// if we have a custom next function, use it.
+	if l.nextFunc != nil {
+		err := l.nextFunc(ctx)
+		if err != nil {
+			return err
+		}
+
 		return nil
 	}
+
+	// render rate limited msg, with our limit code and exit.
+	return ctx.Render(l.limitCode, "Rate Limit Reached")
 }
@@ -1,12 +1,15 @@
 package limiter
 
 import (
+	"context"
 	"log"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/joseph-beck/amp/pkg/amp"
+	amperr "github.com/joseph-beck/amp/pkg/error"
 	"github.com/joseph-beck/amp/pkg/status"
 	"github.com/stretchr/testify/assert"
 )
@@ -119,3 +122,334 @@ func TestNew(t *testing.T) {
 		Debug:       true,
 	}))
 }
+
+func TestNewClientIP(t *testing.T) {
+	a := amp.New(amp.Config{
+		TrustedProxies: []string{"10.0.0.1"},
+	})
+
+	a.Get("/test", func(ctx *amp.Ctx) error {
+		return nil
+	}, New(Config{
+		Limit:    1,
+		Duration: 1 * time.Minute,
+	}))
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	request.RemoteAddr = "192.0.2.1:1234"
+	writer := httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Equal(t, status.OK, writer.Code)
+
+	// clients of the same host are limited separately.
+	request = httptest.NewRequest("GET", "/test", nil)
+	request.RemoteAddr = "192.0.2.2:1234"
+	writer = httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Equal(t, status.OK, writer.Code)
+
+	request = httptest.NewRequest("GET", "/test", nil)
+	request.RemoteAddr = "10.0.0.1:1234"
+	request.Header.Set("X-Forwarded-For", "192.0.2.1")
+	writer = httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Equal(t, status.TooManyRequests, writer.Code)
+}
+
+func TestNewAlgorithm(t *testing.T) {
+	clock := newMockClock()
+	a := amp.New()
+
+	a.Get("/fixed", func(ctx *amp.Ctx) error {
+		return nil
+	}, New(Config{
+		Limit:    1,
+		Duration: 1 * time.Minute,
+		Clock:    clock.Now,
+	}))
+
+	a.Get("/bucket", func(ctx *amp.Ctx) error {
+		return nil
+	}, New(Config{
+		Limit:     1,
+		Duration:  1 * time.Minute,
+		Algorithm: TokenBucket,
+		Burst:     2,
+		Clock:     clock.Now,
+	}))
+
+	serve := func(path string) int {
+		request := httptest.NewRequest("GET", path, nil)
+		writer := httptest.NewRecorder()
+		a.ServeHTTP(writer, request)
+		return writer.Code
+	}
+
+	assert.Equal(t, status.OK, serve("/fixed"))
+	assert.Equal(t, status.TooManyRequests, serve("/fixed"))
+	assert.Equal(t, status.OK, serve("/bucket"))
+	assert.Equal(t, status.OK, serve("/bucket"))
+	assert.Equal(t, status.TooManyRequests, serve("/bucket"))
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, status.TooManyRequests, serve("/fixed"))
+	assert.Equal(t, status.TooManyRequests, serve("/bucket"))
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, status.OK, serve("/fixed"))
+	assert.Equal(t, status.OK, serve("/bucket"))
+	assert.Equal(t, status.TooManyRequests, serve("/bucket"))
+}
+
+func TestNewAlgorithmStorage(t *testing.T) {
+	// algorithms that keep their state in memory cannot share a limit through a Storage.
+	for _, algorithm := range []Algorithm{SlidingLog, TokenBucket} {
+		assert.Panics(t, func() {
+			New(Config{
+				Limit:     1,
+				Duration:  1 * time.Minute,
+				Algorithm: algorithm,
+				Storage:   newStore(),
+			})
+		}, algorithm.String())
+	}
+
+	for _, algorithm := range []Algorithm{FixedWindow, SlidingWindow} {
+		assert.NotPanics(t, func() {
+			New(Config{
+				Limit:     1,
+				Duration:  1 * time.Minute,
+				Algorithm: algorithm,
+				Storage:   newStore(),
+			})
+		}, algorithm.String())
+	}
+}
+
+func TestNewMaxKeys(t *testing.T) {
+	a := amp.New()
+
+	a.Get("/test", func(ctx *amp.Ctx) error {
+		return nil
+	}, New(Config{
+		Limit:    1,
+		Duration: 1 * time.Minute,
+		MaxKeys:  1,
+	}))
+
+	serve := func(addr string) int {
+		request := httptest.NewRequest("GET", "/test", nil)
+		request.RemoteAddr = addr
+		writer := httptest.NewRecorder()
+		a.ServeHTTP(writer, request)
+		return writer.Code
+	}
+
+	assert.Equal(t, status.OK, serve("192.0.2.1:1234"))
+	assert.Equal(t, status.TooManyRequests, serve("192.0.2.1:1234"))
+
+	// the least recently used client is evicted, forgetting its hits.
+	assert.Equal(t, status.OK, serve("192.0.2.2:1234"))
+	assert.Equal(t, status.OK, serve("192.0.2.1:1234"))
+
+	// the janitor is stopped when the Mux is shutdown.
+	assert.NoError(t, a.Shutdown(context.Background()))
+}
+
+func TestNewHeaders(t *testing.T) {
+	clock := newMockClock()
+	a := amp.New()
+
+	a.Get("/test", func(ctx *amp.Ctx) error {
+		quota, ok := GetQuota(ctx)
+		assert.True(t, ok)
+		return ctx.RenderString(status.OK, "%d", quota.Remaining)
+	}, New(Config{
+		Limit:    2,
+		Duration: 1 * time.Minute,
+		Clock:    clock.Now,
+	}))
+
+	a.Get("/disabled", func(ctx *amp.Ctx) error {
+		return nil
+	}, New(Config{
+		Limit:          1,
+		Duration:       1 * time.Minute,
+		Clock:          clock.Now,
+		DisableHeaders: true,
+	}))
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest("GET", path, nil)
+		writer := httptest.NewRecorder()
+		a.ServeHTTP(writer, request)
+		return writer
+	}
+
+	reset := strconv.FormatInt(clock.Now().Add(1*time.Minute).Unix(), 10)
+
+	writer := serve("/test")
+	assert.Equal(t, status.OK, writer.Code)
+	assert.Equal(t, "1", writer.Body.String())
+	assert.Equal(t, "2", writer.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", writer.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", writer.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "2", writer.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", writer.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, reset, writer.Header().Get("X-RateLimit-Reset"))
+	assert.Empty(t, writer.Header().Get("Retry-After"))
+
+	clock.Advance(20*time.Second + 500*time.Millisecond)
+
+	writer = serve("/test")
+	assert.Equal(t, status.OK, writer.Code)
+	assert.Equal(t, "0", writer.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "40", writer.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, reset, writer.Header().Get("X-RateLimit-Reset"))
+
+	// rejected responses tell the client when to retry.
+	writer = serve("/test")
+	assert.Equal(t, status.TooManyRequests, writer.Code)
+	assert.Equal(t, "0", writer.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "40", writer.Header().Get("Retry-After"))
+
+	writer = serve("/disabled")
+	assert.Equal(t, status.OK, writer.Code)
+	assert.Empty(t, writer.Header().Get("RateLimit-Limit"))
+	assert.Empty(t, writer.Header().Get("X-RateLimit-Limit"))
+
+	writer = serve("/disabled")
+	assert.Equal(t, status.TooManyRequests, writer.Code)
+	assert.Empty(t, writer.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", writer.Header().Get("Retry-After"))
+}
+
+func TestGetQuota(t *testing.T) {
+	a := amp.New()
+
+	a.Get("/test", func(ctx *amp.Ctx) error {
+		_, ok := GetQuota(ctx)
+		assert.False(t, ok)
+
+		ctx.Set(QuotaKey, "invalid")
+		_, ok = GetQuota(ctx)
+		assert.False(t, ok)
+		return nil
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Equal(t, status.OK, writer.Code)
+}
+
+func TestNewReserve(t *testing.T) {
+	a := amp.New()
+
+	release := make(chan struct{})
+	a.Get("/slow", func(ctx *amp.Ctx) error {
+		<-release
+		return nil
+	}, New(Config{
+		Limit:    2,
+		Duration: 1 * time.Minute,
+		Reserve:  true,
+	}))
+
+	codes := make(chan int, 5)
+	for range 5 {
+		go func() {
+			request := httptest.NewRequest("GET", "/slow", nil)
+			writer := httptest.NewRecorder()
+			a.ServeHTTP(writer, request)
+			codes <- writer.Code
+		}()
+	}
+
+	// the requests waiting in the handler have reserved the whole quota.
+	for range 3 {
+		assert.Equal(t, status.TooManyRequests, <-codes)
+	}
+
+	close(release)
+	for range 2 {
+		assert.Equal(t, status.OK, <-codes)
+	}
+
+	// skipped requests are refunded once they are done.
+	a.Get("/fails", func(ctx *amp.Ctx) error {
+		quota, ok := GetQuota(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, 0, quota.Remaining)
+
+		return amperr.BadRequest("")
+	}, New(Config{
+		Limit:     1,
+		Duration:  1 * time.Minute,
+		Reserve:   true,
+		SkipFails: true,
+	}))
+
+	for range 3 {
+		request := httptest.NewRequest("GET", "/fails", nil)
+		writer := httptest.NewRecorder()
+		a.ServeHTTP(writer, request)
+		assert.Equal(t, status.BadRequest, writer.Code)
+	}
+}
+
+func TestNewCostFunc(t *testing.T) {
+	a := amp.New()
+
+	a.Get("/test", func(ctx *amp.Ctx) error {
+		return nil
+	}, New(Config{
+		Limit:    5,
+		Duration: 1 * time.Minute,
+		CostFunc: func(ctx *amp.Ctx) int {
+			cost, _ := ctx.QueryInt("cost", 1)
+			return cost
+		},
+	}))
+
+	a.Get("/reserve", func(ctx *amp.Ctx) error {
+		return nil
+	}, New(Config{
+		Limit:    5,
+		Duration: 1 * time.Minute,
+		Reserve:  true,
+		CostFunc: func(ctx *amp.Ctx) int {
+			cost, _ := ctx.QueryInt("cost", 1)
+			return cost
+		},
+	}))
+
+	for _, path := range []string{"/test", "/reserve"} {
+		serve := func(cost int) *httptest.ResponseRecorder {
+			request := httptest.NewRequest("GET", path+"?cost="+strconv.Itoa(cost), nil)
+			writer := httptest.NewRecorder()
+			a.ServeHTTP(writer, request)
+			return writer
+		}
+
+		writer := serve(3)
+		assert.Equal(t, status.OK, writer.Code)
+		assert.Equal(t, "2", writer.Header().Get("RateLimit-Remaining"))
+
+		// the request costs more than remains.
+		writer = serve(3)
+		assert.Equal(t, status.TooManyRequests, writer.Code)
+		assert.Equal(t, "2", writer.Header().Get("RateLimit-Remaining"))
+
+		// requests that cost nothing are not counted.
+		writer = serve(0)
+		assert.Equal(t, status.OK, writer.Code)
+
+		writer = serve(2)
+		assert.Equal(t, status.OK, writer.Code)
+		assert.Equal(t, "0", writer.Header().Get("RateLimit-Remaining"))
+
+		writer = serve(1)
+		assert.Equal(t, status.TooManyRequests, writer.Code)
+	}
+}
@@ -0,0 +1,473 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Limiter is a middleware used for rate limiting requests.
+package limiter
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Configure the RESPStorage.
+type RESPConfig struct {
+	// Address of the server, such as "localhost:6379".
+	Addr string
+
+	// Username used to authenticate, with the Password.
+	// Leave empty to authenticate with only the Password.
+	Username string
+
+	// Password used to authenticate, if empty the connections are not authenticated.
+	Password string
+
+	// Database selected by each connection, if 0 the default database is used.
+	DB int
+
+	// Prefix of every key stored, used to prevent keys clashing with other users of the server.
+	// If this is empty, "amp:limiter:" is used.
+	Prefix string
+
+	// Maximum number of idle connections kept open.
+	// If this is 0, 10 is used.
+	PoolSize int
+
+	// Maximum time to wait when connecting to the server.
+	// If this is 0, 5 * time.Second is used.
+	DialTimeout time.Duration
+
+	// Maximum time to wait for the server to read commands and reply to them.
+	// The deadline of the context is used instead, if it is sooner.
+	// If this is 0, 3 * time.Second is used.
+	Timeout time.Duration
+}
+
+// Storage that uses a server speaking RESP, the Redis serialization protocol, such as Redis or Valkey.
+// Allows the limits to be shared between replicas of a service, and kept across restarts.
+//
+//	limiter.New(limiter.Config{
+//		Limit:    100,
+//		Duration: time.Minute,
+//		Storage:  limiter.NewRESPStorage(limiter.RESPConfig{Addr: "localhost:6379"}),
+//	})
+type RESPStorage struct {
+	// unexported cfg.
+	cfg RESPConfig
+
+	// unexported idle connections.
+	conns chan *respConn
+}
+
+// Create a new RESPStorage, connections are made when they are first needed.
+func NewRESPStorage(cfg RESPConfig) *RESPStorage {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "amp:limiter:"
+	}
+
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = 10
+	}
+
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = 5 * time.Second
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 3 * time.Second
+	}
+
+	return &RESPStorage{
+		cfg:   cfg,
+		conns: make(chan *respConn, cfg.PoolSize),
+	}
+}
+
+// Script that refunds the hits of a key, if it exists, without its hits going below 0.
+// DECRBY keeps the expiry of the key, the hits and time to live of the key are replied.
+const respRefundScript = `local hits = redis.call("GET", KEYS[1])
+if not hits then
+	return {0, -2}
+end
+local n = math.min(tonumber(ARGV[1]), tonumber(hits))
+return {redis.call("DECRBY", KEYS[1], n), redis.call("PTTL", KEYS[1])}`
+
+// Increment the hits of a key by n, in a transaction.
+// The key is created with the expiry if it does not exist, incrementing keeps the expiry it already has.
+// Refunds, a negative n, are ran by a script, so that a key that does not exist, or has expired, is not created.
+func (s *RESPStorage) Increment(ctx context.Context, key string, n int, expiry time.Duration) (int, time.Duration, error) {
+	key = s.cfg.Prefix + key
+	if n < 0 {
+		return s.refund(ctx, key, -n)
+	}
+
+	ms := strconv.FormatInt(max(expiry.Milliseconds(), 1), 10)
+
+	reply, err := s.transaction(ctx,
+		[]string{"SET", key, "0", "PX", ms, "NX"},
+		[]string{"INCRBY", key, strconv.Itoa(n)},
+		[]string{"PTTL", key},
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	hits, err := respInt(reply[1])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ttl, err := respInt(reply[2])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return max(hits, 0), respTTL(ttl), nil
+}
+
+// Refunds n hits of a prefixed key, if it exists, without its hits going below 0.
+func (s *RESPStorage) refund(ctx context.Context, key string, n int) (int, time.Duration, error) {
+	reply, err := s.do(ctx, "EVAL", respRefundScript, "1", key, strconv.Itoa(n))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	elems, ok := reply.([]any)
+	if !ok || len(elems) != 2 {
+		return 0, 0, fmt.Errorf("error, resp: unexpected reply %v", reply)
+	}
+
+	hits, err := respInt(elems[0])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ttl, err := respInt(elems[1])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return max(hits, 0), respTTL(ttl), nil
+}
+
+// Get the hits of a key, 0 if it does not exist or has expired.
+func (s *RESPStorage) Get(ctx context.Context, key string) (int, time.Duration, error) {
+	key = s.cfg.Prefix + key
+
+	reply, err := s.transaction(ctx,
+		[]string{"GET", key},
+		[]string{"PTTL", key},
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if reply[0] == nil {
+		return 0, 0, nil
+	}
+
+	hits, err := respInt(reply[0])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ttl, err := respInt(reply[1])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return max(hits, 0), respTTL(ttl), nil
+}
+
+// Reset the hits of a key.
+func (s *RESPStorage) Reset(ctx context.Context, key string) error {
+	_, err := s.do(ctx, "DEL", s.cfg.Prefix+key)
+	return err
+}
+
+// Close the idle connections of the RESPStorage.
+func (s *RESPStorage) Close() error {
+	errs := make([]error, 0)
+	for {
+		select {
+		case conn := <-s.conns:
+			errs = append(errs, conn.Close())
+		default:
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// Runs commands in a transaction, MULTI and EXEC, returning the reply of each command.
+func (s *RESPStorage) transaction(ctx context.Context, cmds ...[]string) ([]any, error) {
+	cmds = append(append([][]string{{"MULTI"}}, cmds...), []string{"EXEC"})
+
+	replies, err := s.pipeline(ctx, cmds...)
+	if err != nil {
+		return nil, err
+	}
+
+	exec, ok := replies[len(replies)-1].([]any)
+	if !ok || len(exec) != len(cmds)-2 {
+		return nil, errors.New("error, transaction was aborted")
+	}
+
+	return exec, nil
+}
+
+// Runs a command, returning its reply.
+func (s *RESPStorage) do(ctx context.Context, args ...string) (any, error) {
+	replies, err := s.pipeline(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return replies[0], nil
+}
+
+// Writes commands together, then reads the reply of each.
+// A connection is only reused if every reply was read.
+func (s *RESPStorage) pipeline(ctx context.Context, cmds ...[]string) ([]any, error) {
+	conn, err := s.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := conn.pipeline(ctx, cmds...)
+	if err != nil {
+		var respErr respError
+		if !errors.As(err, &respErr) {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	s.put(conn)
+	return replies, err
+}
+
+// Gets an idle connection, or connects to the server.
+func (s *RESPStorage) get(ctx context.Context) (*respConn, error) {
+	select {
+	case conn := <-s.conns:
+		return conn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: s.cfg.DialTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", s.cfg.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &respConn{Conn: netConn, reader: bufio.NewReader(netConn), timeout: s.cfg.Timeout}
+
+	setup := make([][]string, 0, 2)
+	if s.cfg.Password != "" {
+		if s.cfg.Username != "" {
+			setup = append(setup, []string{"AUTH", s.cfg.Username, s.cfg.Password})
+		} else {
+			setup = append(setup, []string{"AUTH", s.cfg.Password})
+		}
+	}
+
+	if s.cfg.DB != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(s.cfg.DB)})
+	}
+
+	if len(setup) > 0 {
+		if _, err := conn.pipeline(ctx, setup...); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+// Returns a connection to the idle connections, closing it if there are too many.
+func (s *RESPStorage) put(conn *respConn) {
+	select {
+	case s.conns <- conn:
+	default:
+		_ = conn.Close()
+	}
+}
+
+// Error replied by the server, the connection can still be used.
+type respError string
+
+// Get the message of the respError.
+func (e respError) Error() string {
+	return "error, resp: " + string(e)
+}
+
+// A connection to a server speaking RESP.
+type respConn struct {
+	net.Conn
+
+	// unexported reader, buffers the replies of the server.
+	reader *bufio.Reader
+
+	// unexported timeout, the maximum time to wait for the server.
+	timeout time.Duration
+}
+
+// Writes commands together, then reads the reply of each.
+// Returns the first error replied by the server, after reading every reply.
+// Waits for the server until the timeout of the connection, or the deadline of the context if it is sooner.
+func (c *respConn) pipeline(ctx context.Context, cmds ...[]string) ([]any, error) {
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	if err := c.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 0, 64)
+	for _, args := range cmds {
+		buf = append(buf, '*')
+		buf = strconv.AppendInt(buf, int64(len(args)), 10)
+		buf = append(buf, '\r', '\n')
+
+		for _, arg := range args {
+			buf = append(buf, '$')
+			buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+			buf = append(buf, '\r', '\n')
+			buf = append(buf, arg...)
+			buf = append(buf, '\r', '\n')
+		}
+	}
+
+	if _, err := c.Write(buf); err != nil {
+		return nil, err
+	}
+
+	var first error
+	replies := make([]any, 0, len(cmds))
+	for range cmds {
+		reply, err := c.read()
+		if err != nil {
+			var respErr respError
+			if !errors.As(err, &respErr) {
+				return nil, err
+			}
+
+			if first == nil {
+				first = err
+			}
+		}
+
+		replies = append(replies, reply)
+	}
+
+	return replies, first
+}
+
+// Reads a reply, as a string, int64, []any, nil or respError.
+func (c *respConn) read() (any, error) {
+	line, err := c.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) == 0 {
+		return nil, errors.New("error, resp: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+
+	case '-':
+		return nil, respError(line[1:])
+
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.reader, buf); err != nil {
+			return nil, err
+		}
+
+		return string(buf[:n]), nil
+
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+
+		// errors of the elements of a transaction are kept as the elements.
+		elems := make([]any, 0, n)
+		for range n {
+			elem, err := c.read()
+			if err != nil {
+				var respErr respError
+				if !errors.As(err, &respErr) {
+					return nil, err
+				}
+
+				elem = respErr
+			}
+
+			elems = append(elems, elem)
+		}
+
+		return elems, nil
+
+	default:
+		return nil, fmt.Errorf("error, resp: unknown reply %q", line)
+	}
+}
+
+// Reads a line of a reply, without the CRLF.
+func (c *respConn) readLine() (string, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", errors.New("error, resp: invalid line")
+	}
+
+	return line[:len(line)-2], nil
+}
+
+// Gets an int from a reply, an integer or a bulk string.
+func respInt(reply any) (int, error) {
+	switch val := reply.(type) {
+	case int64:
+		return int(val), nil
+	case string:
+		return strconv.Atoi(val)
+	case respError:
+		return 0, val
+	default:
+		return 0, fmt.Errorf("error, resp: unexpected reply %v", reply)
+	}
+}
+
+// Converts the milliseconds of a PTTL reply to a duration.
+// Keys without an expiry, -1, or that do not exist, -2, have no time to live.
+func respTTL(ms int) time.Duration {
+	if ms < 0 {
+		return 0
+	}
+
+	return time.Duration(ms) * time.Millisecond
+}
@@ -0,0 +1,499 @@
+package limiter
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joseph-beck/amp/pkg/amp"
+	"github.com/joseph-beck/amp/pkg/status"
+	"github.com/stretchr/testify/assert"
+)
+
+// In process stand in for a server speaking RESP, supporting the commands used by the RESPStorage.
+type respServer struct {
+	listener net.Listener
+
+	// password required by AUTH, if not empty.
+	password string
+
+	mu      sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+}
+
+// Starts a respServer, which is closed when the test finishes.
+func newRESPServer(t *testing.T, password string) *respServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	server := &respServer{
+		listener: listener,
+		password: password,
+		values:   make(map[string]string),
+		expires:  make(map[string]time.Time),
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go server.serve(conn)
+		}
+	}()
+
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	return server
+}
+
+// Get the address of the respServer.
+func (s *respServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+// Get a value of the respServer, as stored.
+func (s *respServer) get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(key)
+	val, ok := s.values[key]
+	return val, ok
+}
+
+// Serves the commands of a connection, queuing those given between MULTI and EXEC.
+func (s *respServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	authed := s.password == ""
+
+	var queue [][]string
+	inMulti := false
+
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+
+		cmd := strings.ToUpper(args[0])
+		switch {
+		case cmd == "AUTH":
+			authed = args[len(args)-1] == s.password
+			if !authed {
+				_, _ = io.WriteString(conn, "-WRONGPASS invalid password\r\n")
+				continue
+			}
+
+			_, _ = io.WriteString(conn, "+OK\r\n")
+
+		case !authed:
+			_, _ = io.WriteString(conn, "-NOAUTH Authentication required.\r\n")
+
+		case cmd == "MULTI":
+			inMulti, queue = true, nil
+			_, _ = io.WriteString(conn, "+OK\r\n")
+
+		case cmd == "EXEC":
+			s.mu.Lock()
+			reply := fmt.Sprintf("*%d\r\n", len(queue))
+			for _, queued := range queue {
+				reply += s.exec(queued)
+			}
+			s.mu.Unlock()
+
+			inMulti, queue = false, nil
+			_, _ = io.WriteString(conn, reply)
+
+		case inMulti:
+			queue = append(queue, args)
+			_, _ = io.WriteString(conn, "+QUEUED\r\n")
+
+		default:
+			s.mu.Lock()
+			reply := s.exec(args)
+			s.mu.Unlock()
+
+			_, _ = io.WriteString(conn, reply)
+		}
+	}
+}
+
+// Runs a command, the mutex must be held.
+func (s *respServer) exec(args []string) string {
+	cmd := strings.ToUpper(args[0])
+	if len(args) > 1 {
+		s.expire(args[1])
+	}
+
+	switch cmd {
+	case "PING":
+		return "+PONG\r\n"
+
+	case "SELECT":
+		return "+OK\r\n"
+
+	case "GET":
+		val, ok := s.values[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(val), val)
+
+	case "SET":
+		key, val := args[1], args[2]
+		nx, px := false, 0
+		for i := 3; i < len(args); i++ {
+			switch strings.ToUpper(args[i]) {
+			case "NX":
+				nx = true
+			case "PX":
+				i++
+				px, _ = strconv.Atoi(args[i])
+			}
+		}
+
+		if _, ok := s.values[key]; ok && nx {
+			return "$-1\r\n"
+		}
+
+		s.values[key] = val
+		delete(s.expires, key)
+		if px > 0 {
+			s.expires[key] = time.Now().Add(time.Duration(px) * time.Millisecond)
+		}
+
+		return "+OK\r\n"
+
+	case "INCRBY":
+		n, err := strconv.Atoi(s.values[args[1]])
+		if _, ok := s.values[args[1]]; ok && err != nil {
+			return "-ERR value is not an integer or out of range\r\n"
+		}
+
+		by, err := strconv.Atoi(args[2])
+		if err != nil {
+			return "-ERR value is not an integer or out of range\r\n"
+		}
+
+		s.values[args[1]] = strconv.Itoa(n + by)
+		return fmt.Sprintf(":%d\r\n", n+by)
+
+	case "EVAL":
+		// only the script used to refund hits is supported.
+		if args[1] != respRefundScript {
+			return "-ERR unknown script\r\n"
+		}
+
+		s.expire(args[3])
+		val, ok := s.values[args[3]]
+		if !ok {
+			return "*2\r\n:0\r\n:-2\r\n"
+		}
+
+		hits, _ := strconv.Atoi(val)
+		by, _ := strconv.Atoi(args[4])
+		hits -= min(by, hits)
+		s.values[args[3]] = strconv.Itoa(hits)
+
+		return fmt.Sprintf("*2\r\n:%d\r\n%s", hits, s.exec([]string{"PTTL", args[3]}))
+
+	case "PTTL":
+		if _, ok := s.values[args[1]]; !ok {
+			return ":-2\r\n"
+		}
+
+		exp, ok := s.expires[args[1]]
+		if !ok {
+			return ":-1\r\n"
+		}
+
+		return fmt.Sprintf(":%d\r\n", time.Until(exp).Milliseconds())
+
+	case "DEL":
+		_, ok := s.values[args[1]]
+		delete(s.values, args[1])
+		delete(s.expires, args[1])
+
+		if ok {
+			return ":1\r\n"
+		}
+
+		return ":0\r\n"
+
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+// Removes a key if it has expired, the mutex must be held.
+func (s *respServer) expire(key string) {
+	if exp, ok := s.expires[key]; ok && !time.Now().Before(exp) {
+		delete(s.values, key)
+		delete(s.expires, key)
+	}
+}
+
+// Reads a command, an array of bulk strings.
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, 0, n)
+	for range n {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+
+		args = append(args, string(buf[:size]))
+	}
+
+	return args, nil
+}
+
+func TestRESPStorage(t *testing.T) {
+	server := newRESPServer(t, "")
+	storage := NewRESPStorage(RESPConfig{Addr: server.addr()})
+	defer storage.Close()
+
+	ctx := context.Background()
+
+	hits, ttl, err := storage.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, hits)
+	assert.Equal(t, time.Duration(0), ttl)
+
+	hits, ttl, err = storage.Increment(ctx, "key", 1, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, hits)
+	assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+	hits, _, err = storage.Increment(ctx, "key", 1, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, hits)
+
+	hits, ttl, err = storage.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, hits)
+	assert.Greater(t, ttl, time.Duration(0))
+
+	// keys can be incremented by more than one, and refunded.
+	hits, _, err = storage.Increment(ctx, "key", 3, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, hits)
+
+	hits, _, err = storage.Increment(ctx, "key", -3, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, hits)
+
+	// keys are prefixed, so that they do not clash with other users of the server.
+	val, ok := server.get("amp:limiter:key")
+	assert.True(t, ok)
+	assert.Equal(t, "2", val)
+
+	err = storage.Reset(ctx, "key")
+	assert.NoError(t, err)
+
+	hits, _, err = storage.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, hits)
+
+	// expired keys start again.
+	hits, _, err = storage.Increment(ctx, "expires", 1, 10*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, hits)
+
+	time.Sleep(20 * time.Millisecond)
+
+	hits, _, err = storage.Increment(ctx, "expires", 1, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, hits)
+
+	// refunds of a key that has expired are ignored, rather than creating it below 0.
+	hits, _, err = storage.Increment(ctx, "refund", 1, 10*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, hits)
+
+	time.Sleep(20 * time.Millisecond)
+
+	hits, ttl, err = storage.Increment(ctx, "refund", -1, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, hits)
+	assert.Equal(t, time.Duration(0), ttl)
+
+	_, ok = server.get("amp:limiter:refund")
+	assert.False(t, ok)
+
+	hits, _, err = storage.Increment(ctx, "refund", 1, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, hits)
+
+	// refunds do not take the hits of a key below 0.
+	hits, ttl, err = storage.Increment(ctx, "refund", -3, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, hits)
+	assert.Greater(t, ttl, time.Duration(0))
+
+	val, ok = server.get("amp:limiter:refund")
+	assert.True(t, ok)
+	assert.Equal(t, "0", val)
+
+	assert.NoError(t, storage.Close())
+}
+
+func TestRESPStorageConfig(t *testing.T) {
+	server := newRESPServer(t, "secret")
+
+	storage := NewRESPStorage(RESPConfig{Addr: server.addr(), Password: "secret", DB: 1, Prefix: "test:"})
+	defer storage.Close()
+
+	hits, _, err := storage.Increment(context.Background(), "key", 1, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, hits)
+
+	_, ok := server.get("test:key")
+	assert.True(t, ok)
+
+	storage = NewRESPStorage(RESPConfig{Addr: server.addr(), Password: "wrong"})
+	defer storage.Close()
+
+	_, _, err = storage.Increment(context.Background(), "key", 1, time.Minute)
+	assert.ErrorContains(t, err, "WRONGPASS")
+
+	storage = NewRESPStorage(RESPConfig{Addr: server.addr()})
+	defer storage.Close()
+
+	_, _, err = storage.Get(context.Background(), "key")
+	assert.ErrorContains(t, err, "NOAUTH")
+
+	storage = NewRESPStorage(RESPConfig{Addr: "127.0.0.1:0", DialTimeout: 100 * time.Millisecond})
+	_, _, err = storage.Get(context.Background(), "key")
+	assert.Error(t, err)
+}
+
+func TestRESPStorageTimeout(t *testing.T) {
+	// a server that reads commands, but never replies.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(io.Discard, conn)
+			}()
+		}
+	}()
+
+	storage := NewRESPStorage(RESPConfig{Addr: listener.Addr().String(), Timeout: 50 * time.Millisecond})
+	defer storage.Close()
+
+	start := time.Now()
+	_, _, err = storage.Increment(context.Background(), "key", 1, time.Minute)
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	assert.Less(t, time.Since(start), 1*time.Second)
+
+	// the deadline of the context is used if it is sooner.
+	storage = NewRESPStorage(RESPConfig{Addr: listener.Addr().String(), Timeout: time.Minute})
+	defer storage.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start = time.Now()
+	_, _, err = storage.Get(ctx, "key")
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	assert.Less(t, time.Since(start), 1*time.Second)
+}
+
+func TestRESPStorageConcurrent(t *testing.T) {
+	server := newRESPServer(t, "")
+	storage := NewRESPStorage(RESPConfig{Addr: server.addr(), PoolSize: 2})
+	defer storage.Close()
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, _, err := storage.Increment(context.Background(), "key", 1, time.Minute)
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	hits, _, err := storage.Get(context.Background(), "key")
+	assert.NoError(t, err)
+	assert.Equal(t, 20, hits)
+}
+
+func TestNewStorage(t *testing.T) {
+	server := newRESPServer(t, "")
+	storage := NewRESPStorage(RESPConfig{Addr: server.addr()})
+	defer storage.Close()
+
+	// replicas of a service share the limit of their storage.
+	replicas := []amp.Mux{amp.New(), amp.New()}
+	for i := range replicas {
+		replicas[i].Get("/test", func(ctx *amp.Ctx) error {
+			return nil
+		}, New(Config{
+			Limit:    2,
+			Duration: 1 * time.Minute,
+			Storage:  storage,
+		}))
+	}
+
+	codes := make([]int, 0, 3)
+	for i := range 3 {
+		request := httptest.NewRequest("GET", "/test", nil)
+		writer := httptest.NewRecorder()
+		replicas[i%2].ServeHTTP(writer, request)
+		codes = append(codes, writer.Code)
+	}
+
+	assert.Equal(t, []int{status.OK, status.OK, status.TooManyRequests}, codes)
+}
@@ -0,0 +1,185 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Limiter is a middleware used for rate limiting requests.
+package limiter
+
+import (
+	"container/list"
+	"hash/maphash"
+	"sync"
+	"time"
+)
+
+// number of shards used by shards, unless there is a smaller max keys.
+const shardCount = 16
+
+// an entry of a shard, kept in the order it was last used.
+type entry[V any] struct {
+	// the key of the entry.
+	key string
+
+	// the value of the entry.
+	value V
+
+	// the time the entry expires, and can be removed by purge.
+	expires time.Time
+}
+
+// a shard of the keys, locked separately from the other shards.
+type shard[V any] struct {
+	// elements of each key, the element holds the entry.
+	elements map[string]*list.Element
+
+	// entries from the most to the least recently used.
+	lru *list.List
+
+	// maximum entries of the shard, 0 for no maximum.
+	max int
+
+	// mutex for the shard.
+	mu sync.Mutex
+}
+
+// map of keys split into shards, so that keys in different shards do not wait for each other.
+// If there is a maximum number of keys, the least recently used key of a shard is evicted to make space.
+type shards[V any] struct {
+	// the shards of the keys.
+	shards []*shard[V]
+
+	// seed used to hash the keys to their shard.
+	seed maphash.Seed
+}
+
+// create new shards, with a maximum number of keys split between them, 0 for no maximum.
+// There are fewer shards if there is a smaller maximum, so that the maximum is kept.
+func newShards[V any](maxKeys int) *shards[V] {
+	count := shardCount
+	if maxKeys > 0 {
+		count = min(count, maxKeys)
+	}
+
+	s := &shards[V]{
+		shards: make([]*shard[V], count),
+		seed:   maphash.MakeSeed(),
+	}
+
+	for i := range s.shards {
+		s.shards[i] = &shard[V]{
+			elements: make(map[string]*list.Element),
+			lru:      list.New(),
+		}
+
+		// split the maximum keys between the shards, the first shards take the remainder.
+		if maxKeys > 0 {
+			s.shards[i].max = maxKeys / count
+			if i < maxKeys%count {
+				s.shards[i].max++
+			}
+		}
+	}
+
+	return s
+}
+
+// gets the shard of a key.
+func (s *shards[V]) shard(key string) *shard[V] {
+	return s.shards[maphash.String(s.seed, key)%uint64(len(s.shards))]
+}
+
+// update the value of a key, while its shard is locked.
+// fn is given the value of the key, and false if it does not exist.
+// fn returns the new value and when it expires, the key is removed if it has already expired.
+func (s *shards[V]) update(key string, now time.Time, fn func(val V, ok bool) (V, time.Time)) {
+	sh := s.shard(key)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	var val V
+	elem, ok := sh.elements[key]
+	if ok {
+		val = elem.Value.(*entry[V]).value
+	}
+
+	val, expires := fn(val, ok)
+	if !expires.After(now) {
+		if ok {
+			sh.lru.Remove(elem)
+			delete(sh.elements, key)
+		}
+
+		return
+	}
+
+	if ok {
+		e := elem.Value.(*entry[V])
+		e.value, e.expires = val, expires
+		sh.lru.MoveToFront(elem)
+		return
+	}
+
+	// evict the least recently used key if the shard is full.
+	if sh.max > 0 && sh.lru.Len() >= sh.max {
+		back := sh.lru.Back()
+		sh.lru.Remove(back)
+		delete(sh.elements, back.Value.(*entry[V]).key)
+	}
+
+	sh.elements[key] = sh.lru.PushFront(&entry[V]{key: key, value: val, expires: expires})
+}
+
+// checks to see if the given key exists.
+func (s *shards[V]) exists(key string) bool {
+	sh := s.shard(key)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	_, ok := sh.elements[key]
+	return ok
+}
+
+// remove a given key.
+func (s *shards[V]) remove(key string) {
+	sh := s.shard(key)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	if elem, ok := sh.elements[key]; ok {
+		sh.lru.Remove(elem)
+		delete(sh.elements, key)
+	}
+}
+
+// removes the keys that have expired at the time now, one shard at a time.
+// Returns the number of keys removed.
+func (s *shards[V]) purge(now time.Time) int {
+	removed := 0
+	for _, sh := range s.shards {
+		sh.mu.Lock()
+		for key, elem := range sh.elements {
+			if !elem.Value.(*entry[V]).expires.After(now) {
+				sh.lru.Remove(elem)
+				delete(sh.elements, key)
+				removed++
+			}
+		}
+		sh.mu.Unlock()
+	}
+
+	return removed
+}
+
+// gets the number of keys, including those that have expired but not been purged.
+func (s *shards[V]) len() int {
+	n := 0
+	for _, sh := range s.shards {
+		sh.mu.Lock()
+		n += sh.lru.Len()
+		sh.mu.Unlock()
+	}
+
+	return n
+}
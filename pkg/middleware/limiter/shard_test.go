@@ -0,0 +1,158 @@
+package limiter
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewShards(t *testing.T) {
+	s := newShards[int](0)
+	assert.Len(t, s.shards, shardCount)
+	for _, sh := range s.shards {
+		assert.Equal(t, 0, sh.max)
+	}
+
+	// the maximum keys are split between the shards.
+	s = newShards[int](100)
+	assert.Len(t, s.shards, shardCount)
+
+	total := 0
+	for _, sh := range s.shards {
+		assert.InDelta(t, 100/shardCount, sh.max, 1)
+		total += sh.max
+	}
+	assert.Equal(t, 100, total)
+
+	// there are fewer shards than a small maximum.
+	s = newShards[int](2)
+	assert.Len(t, s.shards, 2)
+}
+
+func TestShardsUpdate(t *testing.T) {
+	now := time.Now()
+	s := newShards[int](0)
+
+	s.update("1", now, func(val int, ok bool) (int, time.Time) {
+		assert.False(t, ok)
+		return 1, now.Add(time.Minute)
+	})
+	assert.True(t, s.exists("1"))
+
+	s.update("1", now, func(val int, ok bool) (int, time.Time) {
+		assert.True(t, ok)
+		assert.Equal(t, 1, val)
+		return val + 1, now.Add(time.Minute)
+	})
+
+	s.update("1", now, func(val int, ok bool) (int, time.Time) {
+		assert.Equal(t, 2, val)
+		return val, now.Add(time.Minute)
+	})
+
+	// a value that has already expired removes the key.
+	s.update("1", now, func(val int, ok bool) (int, time.Time) {
+		return val, now
+	})
+	assert.False(t, s.exists("1"))
+
+	s.update("2", now, func(val int, ok bool) (int, time.Time) {
+		return 1, time.Time{}
+	})
+	assert.False(t, s.exists("2"))
+	assert.Equal(t, 0, s.len())
+}
+
+func TestShardsEvict(t *testing.T) {
+	now := time.Now()
+	s := newShards[int](1)
+
+	set := func(key string) {
+		s.update(key, now, func(val int, ok bool) (int, time.Time) {
+			return val + 1, now.Add(time.Minute)
+		})
+	}
+
+	set("1")
+	set("2")
+	assert.False(t, s.exists("1"))
+	assert.True(t, s.exists("2"))
+	assert.Equal(t, 1, s.len())
+
+	// the least recently used key is evicted.
+	s = newShards[int](2)
+	s.shards = s.shards[:1]
+	s.shards[0].max = 2
+
+	set("1")
+	set("2")
+	set("1")
+	set("3")
+	assert.True(t, s.exists("1"))
+	assert.False(t, s.exists("2"))
+	assert.True(t, s.exists("3"))
+}
+
+func TestShardsRemove(t *testing.T) {
+	now := time.Now()
+	s := newShards[int](0)
+
+	s.update("1", now, func(val int, ok bool) (int, time.Time) {
+		return 1, now.Add(time.Minute)
+	})
+
+	s.remove("1")
+	assert.False(t, s.exists("1"))
+
+	s.remove("2")
+	assert.Equal(t, 0, s.len())
+}
+
+func TestShardsPurge(t *testing.T) {
+	now := time.Now()
+	s := newShards[int](0)
+
+	for i := range 10 {
+		s.update(strconv.Itoa(i), now, func(val int, ok bool) (int, time.Time) {
+			return i, now.Add(time.Duration(i+1) * time.Second)
+		})
+	}
+	assert.Equal(t, 10, s.len())
+
+	removed := s.purge(now.Add(5 * time.Second))
+	assert.Equal(t, 5, removed)
+	assert.Equal(t, 5, s.len())
+	assert.False(t, s.exists("4"))
+	assert.True(t, s.exists("5"))
+}
+
+func TestShardsConcurrent(t *testing.T) {
+	now := time.Now()
+	s := newShards[int](0)
+
+	var wg sync.WaitGroup
+	for i := range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			s.update(strconv.Itoa(i%10), now, func(val int, ok bool) (int, time.Time) {
+				return val + 1, now.Add(time.Minute)
+			})
+		}()
+	}
+
+	wg.Wait()
+
+	total := 0
+	for i := range 10 {
+		s.update(strconv.Itoa(i), now, func(val int, ok bool) (int, time.Time) {
+			total += val
+			return val, now.Add(time.Minute)
+		})
+	}
+	assert.Equal(t, 100, total)
+}
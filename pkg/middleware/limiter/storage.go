@@ -0,0 +1,29 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Limiter is a middleware used for rate limiting requests.
+package limiter
+
+import (
+	"context"
+	"time"
+)
+
+// Storage of the hits of each key of the rate limiter.
+// Implementations must be safe for concurrent use.
+// A Storage shared between replicas of a service, such as the RESPStorage, enforces one limit across all of them.
+type Storage interface {
+	// Increment the hits of a key by n, atomically.
+	// If the key does not exist, or has expired, its hits start at n and it expires after the given expiry.
+	// A negative n refunds hits, the hits of a key do not go below 0.
+	// Refunds of a key that does not exist, or has expired, are ignored and give 0 hits.
+	// Returns the hits of the key and the time until it expires.
+	Increment(ctx context.Context, key string, n int, expiry time.Duration) (int, time.Duration, error)
+
+	// Get the hits of a key and the time until it expires.
+	// Returns 0 hits if the key does not exist, or has expired.
+	Get(ctx context.Context, key string) (int, time.Duration, error)
+
+	// Reset the hits of a key, removing it from the Storage.
+	Reset(ctx context.Context, key string) error
+}
@@ -5,7 +5,7 @@
 package limiter
 
 import (
-	"sync"
+	"context"
 	"time"
 )
 
@@ -15,118 +15,131 @@ type item struct {
 	// used to compare to the rate limit.
 	hits int
 
-	// time since request, or the time the last request was made.
+	// time since request, or the time the first request of the item was made.
 	// this is compared to the current time to see if it violates the rate limit with the duration.
 	timeSinceRequest time.Time
+
+	// the duration after the first request that the item expires.
+	duration time.Duration
 }
 
 // creates a new empty item, the hits start at 1, and the timeSinceRequest is now.
-func newItem() item {
+func newItem(now time.Time) item {
 	return item{
 		hits:             1,
-		timeSinceRequest: time.Now(),
+		timeSinceRequest: now,
 	}
 }
 
 // increments the item.
-// increments the number of hits.
-func (i *item) increment() {
-	i.hits++
+// increments the number of hits by n, the hits do not go below 0.
+func (i *item) increment(n int) {
+	i.hits = max(i.hits+n, 0)
+}
+
+// checks if the items time since request has expired at the time now.
+func (i *item) expired(now time.Time, duration time.Duration) bool {
+	return now.Sub(i.timeSinceRequest) >= duration
 }
 
-// checks if the items time since request has expired.
-func (i *item) expired(duration time.Duration) bool {
-	return time.Since(i.timeSinceRequest) >= duration
+// gets the time until the item expires, from the time now.
+func (i *item) ttl(now time.Time) time.Duration {
+	return i.duration - now.Sub(i.timeSinceRequest)
 }
 
-// use to store a map[string]any of key for the rate limiter.
+// in memory Storage, used by default.
+// stores the items of the keys for the rate limiter, in shards.
 type store struct {
-	// map string item, for storing all items of the store.
+	// shards of items, for storing all items of the store.
 	// the key, either the origin or created by the key generator of type string.
 	// the item being created or iterated on depending on the request.
-	items map[string]item
+	items *shards[item]
 
-	// mutex for the store.
-	// prevents any data races by locking during access.
-	mu sync.Mutex
+	// clock of the store, gives the current time used to expire items.
+	clock func() time.Time
 }
 
-// create a new store with an empty map and mutex.
-func newStore() store {
-	return store{
-		items: make(map[string]item, 0),
-		mu:    sync.Mutex{},
-	}
+// Create a new in memory Storage, the default Storage of the rate limiter.
+// Limits are not shared between replicas of a service, and are lost on restart.
+// Expired keys are removed as they are used, or by the janitor of a rate limiter using the Storage.
+func NewMemoryStorage() Storage {
+	return newStore()
 }
 
-// get an item from the items, map string item.
-// will return new item if it does not exist.
-func (s *store) get(key string) item {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// create a new store with no maximum keys.
+func newStore() *store {
+	return newStoreWithMax(0)
+}
 
-	val, ok := s.items[key]
-	if !ok {
-		return newItem()
+// create a new store that evicts the least recently used keys once it has the max keys.
+func newStoreWithMax(maxKeys int) *store {
+	return &store{
+		items: newShards[item](maxKeys),
+		clock: time.Now,
 	}
+}
 
-	return val
+// Increment the hits of a key by n, creating a new item if it does not exist or has expired.
+// Refunds of a key that does not exist, or has expired, are ignored.
+func (s *store) Increment(ctx context.Context, key string, n int, expiry time.Duration) (int, time.Duration, error) {
+	now := s.clock()
+
+	var hits int
+	var ttl time.Duration
+	s.items.update(key, now, func(val item, ok bool) (item, time.Time) {
+		if !ok || val.expired(now, val.duration) {
+			if n <= 0 {
+				return val, val.timeSinceRequest.Add(val.duration)
+			}
+
+			val = newItem(now)
+			val.hits = n
+			val.duration = expiry
+		} else {
+			val.increment(n)
+		}
+
+		hits, ttl = val.hits, val.ttl(now)
+		return val, val.timeSinceRequest.Add(val.duration)
+	})
+
+	return hits, ttl, nil
 }
 
-// add an item to the store.
-// if it does not exist a new item is made.
-// otherwise the item is incremented.
-func (s *store) insert(key string) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// Get the hits of a key, 0 if it does not exist or has expired.
+func (s *store) Get(ctx context.Context, key string) (int, time.Duration, error) {
+	now := s.clock()
 
-	val, ok := s.items[key]
-	if !ok {
-		s.items[key] = newItem()
+	var hits int
+	var ttl time.Duration
+	s.items.update(key, now, func(val item, ok bool) (item, time.Time) {
+		if ok && !val.expired(now, val.duration) {
+			hits, ttl = val.hits, val.ttl(now)
+		}
 
-		return
-	}
+		return val, val.timeSinceRequest.Add(val.duration)
+	})
 
-	val.increment()
-	s.items[key] = val
+	return hits, ttl, nil
 }
 
-// checks to see if the given key exists in the store.
-func (s *store) exists(key string) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	_, ok := s.items[key]
-	return ok
+// Reset the hits of a key.
+func (s *store) Reset(ctx context.Context, key string) error {
+	s.remove(key)
+	return nil
 }
 
-// checks to see if the item of the given key is currently rate limited.
-// uses duration and limit comparisons.
-func (s *store) limited(key string, duration time.Duration, limit int) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	val, ok := s.items[key]
-	if !ok {
-		return false
-	}
-
-	exp := val.expired(duration)
-	if exp {
-		return false
-	}
-
-	if val.hits >= limit {
-		return true
-	}
+// removes the expired items of the store.
+func (s *store) purge(now time.Time) int {
+	return s.items.purge(now)
+}
 
-	return false
+// checks to see if the given key exists in the store.
+func (s *store) exists(key string) bool {
+	return s.items.exists(key)
 }
 
 // remove a given key from the store.
 func (s *store) remove(key string) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	delete(s.items, key)
+	s.items.remove(key)
 }
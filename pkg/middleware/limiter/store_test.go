@@ -1,6 +1,7 @@
 package limiter
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -8,60 +9,180 @@ import (
 )
 
 func TestNewItem(t *testing.T) {
-	item := newItem()
+	now := time.Now()
+	item := newItem(now)
 	assert.Equal(t, 1, item.hits)
+	assert.Equal(t, now, item.timeSinceRequest)
 }
 
 func TestItemIncrement(t *testing.T) {
-	item := newItem()
-	item.increment()
+	item := newItem(time.Now())
+	item.increment(1)
 	assert.Equal(t, 2, item.hits)
+	item.increment(3)
+	assert.Equal(t, 5, item.hits)
+	item.increment(-10)
+	assert.Equal(t, 0, item.hits)
 }
 
 func TestItemExpired(t *testing.T) {
-	item := newItem()
-	exp := item.expired(0 * time.Second)
+	now := time.Now()
+	item := newItem(now)
+	exp := item.expired(now, 0*time.Second)
 	assert.True(t, exp)
-	exp = item.expired(1 * time.Second)
+	exp = item.expired(now, 1*time.Second)
 	assert.False(t, exp)
+	exp = item.expired(now.Add(1*time.Second), 1*time.Second)
+	assert.True(t, exp)
 }
 
 func TestNewStore(t *testing.T) {
 	store := newStore()
-	assert.Equal(t, make(map[string]item), store.items)
+	assert.Equal(t, 0, store.items.len())
+}
+
+func TestStoreIncrement(t *testing.T) {
+	store := newStore()
+
+	hits, ttl, err := store.Increment(context.Background(), "1", 1, 1*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, hits)
+	assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+	hits, _, err = store.Increment(context.Background(), "1", 1, 1*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, hits)
+
+	// expired keys start again.
+	hits, _, err = store.Increment(context.Background(), "2", 1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, hits)
+
+	hits, _, err = store.Increment(context.Background(), "2", 1, 1*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, hits)
 }
 
 func TestStoreGet(t *testing.T) {
 	store := newStore()
-	store.insert("1")
-	assert.Equal(t, store.get("1").hits, 1)
+
+	hits, ttl, err := store.Get(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, hits)
+	assert.Equal(t, time.Duration(0), ttl)
+
+	_, _, err = store.Increment(context.Background(), "1", 1, 1*time.Minute)
+	assert.NoError(t, err)
+
+	hits, ttl, err = store.Get(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, hits)
+	assert.Greater(t, ttl, time.Duration(0))
+
+	_, _, err = store.Increment(context.Background(), "2", 1, 0)
+	assert.NoError(t, err)
+
+	hits, _, err = store.Get(context.Background(), "2")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, hits)
 }
 
-func TestStoreInsert(t *testing.T) {
+func TestStoreReset(t *testing.T) {
 	store := newStore()
-	store.insert("1")
-	assert.True(t, store.exists("1"))
+	_, _, err := store.Increment(context.Background(), "1", 1, 1*time.Minute)
+	assert.NoError(t, err)
+
+	err = store.Reset(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.False(t, store.exists("1"))
 }
 
 func TestStoreExists(t *testing.T) {
 	store := newStore()
 	assert.False(t, store.exists("1"))
-	store.insert("1")
+	_, _, err := store.Increment(context.Background(), "1", 1, 1*time.Minute)
+	assert.NoError(t, err)
 	assert.True(t, store.exists("1"))
 }
 
-func TestStoreLimited(t *testing.T) {
+func TestStoreRemove(t *testing.T) {
 	store := newStore()
-	store.insert("1")
-	assert.True(t, store.limited("1", 1*time.Minute, 1))
-	assert.False(t, store.limited("1", 1*time.Minute, 2))
-	assert.False(t, store.limited("1", 0*time.Minute, 2))
-	assert.False(t, store.limited("2", 1*time.Minute, 2))
+	_, _, err := store.Increment(context.Background(), "1", 1, 1*time.Minute)
+	assert.NoError(t, err)
+	store.remove("1")
+	assert.False(t, store.exists("1"))
 }
 
-func TestStoreRemove(t *testing.T) {
+func TestStoreClock(t *testing.T) {
+	clock := newMockClock()
 	store := newStore()
-	store.insert("1")
-	store.remove("1")
+	store.clock = clock.Now
+
+	_, ttl, err := store.Increment(context.Background(), "1", 1, 1*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 1*time.Minute, ttl)
+
+	clock.Advance(59 * time.Second)
+
+	hits, ttl, err := store.Get(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, hits)
+	assert.Equal(t, 1*time.Second, ttl)
+
+	clock.Advance(1 * time.Second)
+
+	hits, _, err = store.Get(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, hits)
+}
+
+func TestStorePurge(t *testing.T) {
+	clock := newMockClock()
+	store := newStore()
+	store.clock = clock.Now
+
+	_, _, err := store.Increment(context.Background(), "1", 1, 1*time.Minute)
+	assert.NoError(t, err)
+	_, _, err = store.Increment(context.Background(), "2", 1, 2*time.Minute)
+	assert.NoError(t, err)
+
+	clock.Advance(1 * time.Minute)
+	assert.Equal(t, 1, store.purge(clock.Now()))
 	assert.False(t, store.exists("1"))
+	assert.True(t, store.exists("2"))
+}
+
+func TestStoreMax(t *testing.T) {
+	store := newStoreWithMax(1)
+
+	_, _, err := store.Increment(context.Background(), "1", 1, 1*time.Minute)
+	assert.NoError(t, err)
+	_, _, err = store.Increment(context.Background(), "2", 1, 1*time.Minute)
+	assert.NoError(t, err)
+
+	assert.False(t, store.exists("1"))
+	assert.True(t, store.exists("2"))
+}
+
+func TestStoreIncrementBy(t *testing.T) {
+	store := newStore()
+
+	hits, _, err := store.Increment(context.Background(), "1", 3, 1*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, hits)
+
+	hits, _, err = store.Increment(context.Background(), "1", -2, 1*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, hits)
+
+	hits, _, err = store.Increment(context.Background(), "1", -2, 1*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, hits)
+
+	// refunds of keys that do not exist are ignored.
+	hits, ttl, err := store.Increment(context.Background(), "2", -1, 1*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, hits)
+	assert.Equal(t, time.Duration(0), ttl)
+	assert.False(t, store.exists("2"))
 }
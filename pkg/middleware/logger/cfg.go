@@ -0,0 +1,57 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Logger is a middleware used for logging requests.
+package logger
+
+import (
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/joseph-beck/amp/pkg/amp"
+)
+
+// Format of the access log.
+type Format int
+
+const (
+	// Each request is logged as a line of JSON, using slog.
+	JSON Format = iota
+
+	// Each request is logged as a line of key=value pairs, using slog.
+	Logfmt
+
+	// Each request is logged in the Apache combined log format.
+	Combined
+)
+
+// Configure the Amp Logger middleware.
+type Config struct {
+	// Using the amp.Ctx, the middleware can be skipped if this function returns true.
+	// When using the Default(), SkipFunc will be nil.
+	SkipFunc func(ctx *amp.Ctx) bool
+
+	// Format of the access log.
+	// When using the Default(), Format is JSON.
+	Format Format
+
+	// Where the access log is written to.
+	// When using the Default(), Output is os.Stdout.
+	Output io.Writer
+
+	// Logger used for the JSON and Logfmt formats, instead of the Output.
+	// The format is then decided by the handler of the Logger.
+	// When using the Default(), Logger will be nil.
+	Logger *slog.Logger
+}
+
+// Returns the default configuration for the logger middleware.
+func Default() Config {
+	return Config{
+		SkipFunc: nil,
+		Format:   JSON,
+		Output:   os.Stdout,
+		Logger:   nil,
+	}
+}
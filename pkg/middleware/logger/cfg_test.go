@@ -0,0 +1,16 @@
+package logger
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefault(t *testing.T) {
+	cfg := Default()
+	assert.Nil(t, cfg.SkipFunc)
+	assert.Equal(t, JSON, cfg.Format)
+	assert.Equal(t, os.Stdout, cfg.Output)
+	assert.Nil(t, cfg.Logger)
+}
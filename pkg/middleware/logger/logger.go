@@ -0,0 +1,199 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Logger is a middleware used for logging requests.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joseph-beck/amp/pkg/amp"
+)
+
+// unexported logger struct, used to store our logger settings privately.
+type logger struct {
+	// unexported skipFunc function.
+	skipFunc func(ctx *amp.Ctx) bool
+
+	// unexported format.
+	format Format
+
+	// unexported output.
+	// only used by the Combined format.
+	output io.Writer
+
+	// unexported outputMu.
+	// prevents lines of the Combined format being written at the same time.
+	outputMu *sync.Mutex
+
+	// unexported logger.
+	// used by the JSON and Logfmt formats.
+	logger *slog.Logger
+}
+
+// Create a new logger middleware.
+// If this is given a config it will use that, otherwise Default() config is used.
+// Each request is logged once it has finished, with its latency, bytes, client IP, user agent, route and status.
+// Errors returned by the rest of the chain are responded to by the middleware, so that their status is logged,
+// and are not returned to the middleware before it.
+//
+//	a := amp.New(amp.Config{
+//		Port:  8080,
+//		Quiet: true,
+//	})
+//
+//	a.Use(logger.New())
+func New(args ...Config) amp.Handler {
+	cfg := Default()
+
+	if len(args) > 0 {
+		cfg = args[0]
+	}
+
+	logger := logger{
+		skipFunc: cfg.SkipFunc,
+		format:   cfg.Format,
+		output:   cfg.Output,
+		outputMu: &sync.Mutex{},
+		logger:   cfg.Logger,
+	}
+
+	if logger.output == nil {
+		logger.output = os.Stdout
+	}
+
+	// lets create a logger from the output if we were not given one.
+	if logger.logger == nil {
+		switch logger.format {
+		case Logfmt:
+			logger.logger = slog.New(slog.NewTextHandler(logger.output, nil))
+		default:
+			logger.logger = slog.New(slog.NewJSONHandler(logger.output, nil))
+		}
+	}
+
+	return func(ctx *amp.Ctx) error {
+		if logger.skipFunc != nil && logger.skipFunc(ctx) {
+			return nil
+		}
+
+		start := time.Now()
+
+		err := ctx.Next()
+		if err != nil {
+			ctx.HandleError(err)
+		}
+
+		latency := time.Since(start)
+
+		if logger.format == Combined {
+			logger.combined(ctx, start)
+			return nil
+		}
+
+		logger.log(ctx, latency)
+		return nil
+	}
+}
+
+// Logs the request of the Ctx using slog.
+// Server errors are logged at the error level, and client errors at the warn level.
+func (l *logger) log(ctx *amp.Ctx, latency time.Duration) {
+	level := slog.LevelInfo
+	switch {
+	case ctx.GetStatus() >= 500:
+		level = slog.LevelError
+	case ctx.GetStatus() >= 400:
+		level = slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", ctx.Method()),
+		slog.String("path", ctx.Path()),
+		slog.String("route", route(ctx)),
+		slog.Int("status", ctx.GetStatus()),
+		slog.Duration("latency", latency),
+		slog.Int("bytes", ctx.Size()),
+		slog.String("client_ip", ctx.ClientIP()),
+		slog.String("user_agent", ctx.Request().UserAgent()),
+	}
+
+	if id := ctx.RequestID(); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+
+	l.logger.LogAttrs(context.Background(), level, "request", attrs...)
+}
+
+// Writes the request of the Ctx in the Apache combined log format.
+//
+//	127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08"
+func (l *logger) combined(ctx *amp.Ctx, start time.Time) {
+	request := ctx.Request()
+
+	user := "-"
+	if username, _, ok := request.BasicAuth(); ok && username != "" {
+		user = escape(username)
+	}
+
+	uri := request.RequestURI
+	if uri == "" {
+		uri = request.URL.RequestURI()
+	}
+
+	size := "-"
+	if ctx.Size() > 0 {
+		size = fmt.Sprint(ctx.Size())
+	}
+
+	line := fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s \"%s\" \"%s\"\n",
+		ctx.ClientIP(),
+		user,
+		start.Format("02/Jan/2006:15:04:05 -0700"),
+		escape(request.Method),
+		escape(uri),
+		escape(request.Proto),
+		ctx.GetStatus(),
+		size,
+		escapeOr(request.Referer()),
+		escapeOr(request.UserAgent()),
+	)
+
+	l.outputMu.Lock()
+	defer l.outputMu.Unlock()
+
+	_, _ = io.WriteString(l.output, line)
+}
+
+// Get the route pattern that matched the request of the Ctx, without the method.
+// Empty if no route matched, for example a status.NotFound.
+func route(ctx *amp.Ctx) string {
+	pattern := ctx.Request().Pattern
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		return path
+	}
+
+	return pattern
+}
+
+// Escapes quotes, backslashes and control characters, so values cannot break the line.
+func escape(s string) string {
+	quoted := fmt.Sprintf("%q", s)
+	return quoted[1 : len(quoted)-1]
+}
+
+// Escapes a value, or gives "-" if the value is empty.
+func escapeOr(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return escape(s)
+}
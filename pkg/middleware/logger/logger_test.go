@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/joseph-beck/amp/pkg/amp"
+	amperr "github.com/joseph-beck/amp/pkg/error"
+	"github.com/joseph-beck/amp/pkg/status"
+	"github.com/stretchr/testify/assert"
+)
+
+func quiet() amp.Mux {
+	return amp.New(amp.Config{
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Quiet:  true,
+	})
+}
+
+func TestNewJSON(t *testing.T) {
+	output := bytes.Buffer{}
+
+	a := quiet()
+
+	a.Use(New(Config{
+		Format: JSON,
+		Output: &output,
+	}))
+
+	a.Get("/test/{id}", func(ctx *amp.Ctx) error {
+		ctx.SetRequestID("id")
+		return ctx.Render(status.Created, "hello")
+	})
+
+	request := httptest.NewRequest("GET", "/test/1", nil)
+	request.Header.Set("User-Agent", "test-agent")
+	writer := httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+
+	line := map[string]any{}
+	err := json.Unmarshal(output.Bytes(), &line)
+	assert.NoError(t, err)
+	assert.Equal(t, "INFO", line["level"])
+	assert.Equal(t, "request", line["msg"])
+	assert.Equal(t, "GET", line["method"])
+	assert.Equal(t, "/test/1", line["path"])
+	assert.Equal(t, "/test/{id}", line["route"])
+	assert.Equal(t, float64(status.Created), line["status"])
+	assert.Equal(t, float64(5), line["bytes"])
+	assert.Equal(t, "192.0.2.1", line["client_ip"])
+	assert.Equal(t, "test-agent", line["user_agent"])
+	assert.Equal(t, "id", line["request_id"])
+	assert.Contains(t, line, "latency")
+
+	// requests that match no route have an empty route.
+	output.Reset()
+
+	request = httptest.NewRequest("GET", "/missing", nil)
+	writer = httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Equal(t, status.NotFound, writer.Code)
+
+	line = map[string]any{}
+	err = json.Unmarshal(output.Bytes(), &line)
+	assert.NoError(t, err)
+	assert.Equal(t, "WARN", line["level"])
+	assert.Equal(t, "/missing", line["path"])
+	assert.Equal(t, "", line["route"])
+	assert.Equal(t, float64(status.NotFound), line["status"])
+}
+
+func TestNewLogfmt(t *testing.T) {
+	output := bytes.Buffer{}
+
+	a := quiet()
+
+	a.Use(New(Config{
+		Format: Logfmt,
+		Output: &output,
+	}))
+
+	a.Get("/test", func(ctx *amp.Ctx) error {
+		return amperr.NotFound("missing")
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Equal(t, status.NotFound, writer.Code)
+	assert.Contains(t, output.String(), "level=WARN")
+	assert.Contains(t, output.String(), "status=404")
+	assert.Contains(t, output.String(), "route=/test")
+}
+
+func TestNewCombined(t *testing.T) {
+	output := bytes.Buffer{}
+
+	a := quiet()
+
+	a.Use(New(Config{
+		Format: Combined,
+		Output: &output,
+	}))
+
+	a.Get("/test", func(ctx *amp.Ctx) error {
+		return ctx.Render(status.OK, "hello")
+	})
+
+	request := httptest.NewRequest("GET", "/test?a=1", nil)
+	request.SetBasicAuth("frank", "password")
+	request.Header.Set("Referer", "http://example.com")
+	request.Header.Set("User-Agent", "agent \"quoted\"")
+	writer := httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+
+	pattern := `^192\.0\.2\.1 - frank \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /test\?a=1 HTTP/1\.1" 200 5 "http://example\.com" "agent \\"quoted\\""` + "\n$"
+	assert.Regexp(t, regexp.MustCompile(pattern), output.String())
+}
+
+func TestNewLogger(t *testing.T) {
+	output := bytes.Buffer{}
+
+	a := quiet()
+
+	a.Use(New(Config{
+		Logger: slog.New(slog.NewTextHandler(&output, nil)),
+		SkipFunc: func(ctx *amp.Ctx) bool {
+			return ctx.Path() == "/skip"
+		},
+	}))
+
+	a.Get("/test", func(ctx *amp.Ctx) error {
+		panic("should not be handled")
+	}, func(ctx *amp.Ctx) error {
+		ctx.Abort()
+		return amperr.InternalServerError("")
+	})
+
+	a.Get("/skip", func(ctx *amp.Ctx) error {
+		return nil
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Equal(t, status.InternalServerError, writer.Code)
+	assert.Contains(t, output.String(), "level=ERROR")
+	assert.Contains(t, output.String(), "status=500")
+
+	output.Reset()
+
+	request = httptest.NewRequest("GET", "/skip", nil)
+	writer = httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Equal(t, "", output.String())
+}
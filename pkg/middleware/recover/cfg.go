@@ -0,0 +1,45 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Recover is a middleware used for recovering from panics.
+package recover
+
+import (
+	"fmt"
+
+	"github.com/joseph-beck/amp/pkg/amp"
+	amperr "github.com/joseph-beck/amp/pkg/error"
+)
+
+// Configure the Amp Recover middleware.
+type Config struct {
+	// Handler that is called once a panic has been recovered, given the value of the panic.
+	// The Ctx is aborted before this is called, the returned error is handled by the Mux.
+	// When using the Default(), PanicHandler returns a status.InternalServerError,
+	// which the Mux renders as problem details.
+	PanicHandler func(ctx *amp.Ctx, v any) error
+
+	// Include the stack trace of the panic when logging it.
+	// When using the Default(), StackTrace is true.
+	StackTrace bool
+
+	// Panic again if the panic is a http.ErrAbortHandler,
+	// allowing net/http to abort the response without logging.
+	// When using the Default(), RepanicAbort is true.
+	RepanicAbort bool
+}
+
+// The default PanicHandler, returns a status.InternalServerError.
+// The value of the panic is used as the cause, so it is never given to the client.
+func DefaultPanicHandler(ctx *amp.Ctx, v any) error {
+	return amperr.InternalServerError("").WithCause(fmt.Errorf("panic: %v", v))
+}
+
+// Returns the default configuration for the recover middleware.
+func Default() Config {
+	return Config{
+		PanicHandler: DefaultPanicHandler,
+		StackTrace:   true,
+		RepanicAbort: true,
+	}
+}
@@ -0,0 +1,14 @@
+package recover
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefault(t *testing.T) {
+	cfg := Default()
+	assert.NotNil(t, cfg.PanicHandler)
+	assert.True(t, cfg.StackTrace)
+	assert.True(t, cfg.RepanicAbort)
+}
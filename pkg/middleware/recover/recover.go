@@ -0,0 +1,84 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Recover is a middleware used for recovering from panics.
+package recover
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/joseph-beck/amp/pkg/amp"
+)
+
+// unexported recoverer struct, used to store our recover settings privately.
+type recoverer struct {
+	// unexported panicHandler.
+	// if this is nil, DefaultPanicHandler is used.
+	panicHandler func(ctx *amp.Ctx, v any) error
+
+	// unexported stackTrace.
+	stackTrace bool
+
+	// unexported repanicAbort.
+	repanicAbort bool
+}
+
+// Create a new recover middleware.
+// If this is given a config it will use that, otherwise Default() config is used.
+// Should be the first middleware used by the Mux, so that it can recover from panics in the whole chain.
+//
+//	a := amp.New()
+//
+//	a.Use(recover.New())
+func New(args ...Config) amp.Handler {
+	cfg := Default()
+
+	if len(args) > 0 {
+		cfg = args[0]
+	}
+
+	recoverer := recoverer{
+		panicHandler: DefaultPanicHandler,
+		stackTrace:   cfg.StackTrace,
+		repanicAbort: cfg.RepanicAbort,
+	}
+
+	// lets set the panic handler if we have one.
+	if cfg.PanicHandler != nil {
+		recoverer.panicHandler = cfg.PanicHandler
+	}
+
+	return func(ctx *amp.Ctx) (err error) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+
+			// let net/http deal with aborted handlers.
+			if e, ok := v.(error); ok && recoverer.repanicAbort && errors.Is(e, http.ErrAbortHandler) {
+				panic(v)
+			}
+
+			attrs := []any{
+				"method", ctx.Method(),
+				"path", ctx.Path(),
+				"panic", v,
+			}
+
+			if recoverer.stackTrace {
+				attrs = append(attrs, "stack", string(debug.Stack()))
+			}
+
+			ctx.Logger().Error("recovered from panic", attrs...)
+
+			ctx.Abort()
+			err = recoverer.panicHandler(ctx, v)
+		}()
+
+		// the rest of the chain runs within Next, so any panic will be recovered above.
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,92 @@
+package recover
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joseph-beck/amp/pkg/amp"
+	"github.com/joseph-beck/amp/pkg/status"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	a := amp.New()
+
+	a.Use(New())
+
+	a.Get("/test/one", func(ctx *amp.Ctx) error {
+		panic("boom")
+	})
+
+	request := httptest.NewRequest("GET", "/test/one", nil)
+	writer := httptest.NewRecorder()
+	assert.NotPanics(t, func() {
+		a.ServeHTTP(writer, request)
+	})
+	assert.Equal(t, status.InternalServerError, writer.Code)
+	assert.NotContains(t, writer.Body.String(), "boom")
+
+	a.Get("/test/two", func(ctx *amp.Ctx) error {
+		return ctx.Render(status.OK, "ok")
+	}, func(ctx *amp.Ctx) error {
+		if err := ctx.Next(); err != nil {
+			return err
+		}
+
+		panic("after next")
+	})
+
+	request = httptest.NewRequest("GET", "/test/two", nil)
+	writer = httptest.NewRecorder()
+	assert.NotPanics(t, func() {
+		a.ServeHTTP(writer, request)
+	})
+
+	a.Get("/test/three", func(ctx *amp.Ctx) error {
+		panic(http.ErrAbortHandler)
+	})
+
+	request = httptest.NewRequest("GET", "/test/three", nil)
+	writer = httptest.NewRecorder()
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		a.ServeHTTP(writer, request)
+	})
+}
+
+func TestNewPanicHandler(t *testing.T) {
+	a := amp.New()
+
+	var recovered any
+
+	a.Use(New(Config{
+		PanicHandler: func(ctx *amp.Ctx, v any) error {
+			recovered = v
+			return ctx.Render(status.ServiceUnavailable, "recovered")
+		},
+		StackTrace:   false,
+		RepanicAbort: false,
+	}))
+
+	a.Get("/test/one", func(ctx *amp.Ctx) error {
+		panic("boom")
+	})
+
+	request := httptest.NewRequest("GET", "/test/one", nil)
+	writer := httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Equal(t, "boom", recovered)
+	assert.Equal(t, status.ServiceUnavailable, writer.Code)
+	assert.Equal(t, "recovered", writer.Body.String())
+
+	a.Get("/test/two", func(ctx *amp.Ctx) error {
+		panic(http.ErrAbortHandler)
+	})
+
+	request = httptest.NewRequest("GET", "/test/two", nil)
+	writer = httptest.NewRecorder()
+	assert.NotPanics(t, func() {
+		a.ServeHTTP(writer, request)
+	})
+	assert.Equal(t, http.ErrAbortHandler, recovered)
+}
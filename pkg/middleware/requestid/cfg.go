@@ -0,0 +1,62 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package RequestID is a middleware used for identifying requests.
+package requestid
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// Configure the Amp RequestID middleware.
+type Config struct {
+	// Header the ID is read from, and echoed on the response with.
+	// When using the Default(), Header is "X-Request-ID".
+	Header string
+
+	// Creates a new ID, used when the request does not have a valid one.
+	// When using the Default(), Generator is DefaultGenerator.
+	Generator func() string
+
+	// Checks if the ID given by the request can be used.
+	// When using the Default(), Validator is DefaultValidator.
+	Validator func(id string) bool
+}
+
+// The default Generator, creates a random version 4 UUID.
+func DefaultGenerator() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	// set the version and variant bits of the UUID.
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// The default Validator, accepts IDs of up to 128 printable ASCII characters.
+// Prevents clients from using the ID to inject into logs or headers.
+func DefaultValidator(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Returns the default configuration for the requestid middleware.
+func Default() Config {
+	return Config{
+		Header:    "X-Request-ID",
+		Generator: DefaultGenerator,
+		Validator: DefaultValidator,
+	}
+}
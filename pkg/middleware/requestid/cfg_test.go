@@ -0,0 +1,31 @@
+package requestid
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefault(t *testing.T) {
+	cfg := Default()
+	assert.Equal(t, "X-Request-ID", cfg.Header)
+	assert.NotNil(t, cfg.Generator)
+	assert.NotNil(t, cfg.Validator)
+}
+
+func TestDefaultGenerator(t *testing.T) {
+	id := DefaultGenerator()
+	assert.Len(t, id, 36)
+	assert.Equal(t, "4", id[14:15])
+	assert.True(t, DefaultValidator(id))
+	assert.NotEqual(t, id, DefaultGenerator())
+}
+
+func TestDefaultValidator(t *testing.T) {
+	assert.True(t, DefaultValidator("abc-123"))
+	assert.False(t, DefaultValidator(""))
+	assert.False(t, DefaultValidator("abc 123"))
+	assert.False(t, DefaultValidator("abc\n123"))
+	assert.False(t, DefaultValidator(strings.Repeat("a", 129)))
+}
@@ -0,0 +1,84 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package RequestID is a middleware used for identifying requests.
+package requestid
+
+import (
+	"context"
+
+	"github.com/joseph-beck/amp/pkg/amp"
+)
+
+// Key of the ID in the request context.
+type contextKey struct{}
+
+// unexported requestIDer struct, used to store our request id settings privately.
+type requestIDer struct {
+	// unexported header.
+	header string
+
+	// unexported generator.
+	// if this is nil, DefaultGenerator is used.
+	generator func() string
+
+	// unexported validator.
+	// if this is nil, DefaultValidator is used.
+	validator func(id string) bool
+}
+
+// Create a new requestid middleware.
+// If this is given a config it will use that, otherwise Default() config is used.
+// The ID is stored on the Ctx, ctx.RequestID(), and in the request context, FromContext(ctx),
+// so that it can be given to other services.
+// Should be one of the first middleware used by the Mux, so that the ID is in all of its logs.
+//
+//	a := amp.New()
+//
+//	a.Use(requestid.New())
+func New(args ...Config) amp.Handler {
+	cfg := Default()
+
+	if len(args) > 0 {
+		cfg = args[0]
+	}
+
+	requestIDer := requestIDer{
+		header:    cfg.Header,
+		generator: DefaultGenerator,
+		validator: DefaultValidator,
+	}
+
+	// lets set the generator and validator if we have them.
+	if cfg.Generator != nil {
+		requestIDer.generator = cfg.Generator
+	}
+
+	if cfg.Validator != nil {
+		requestIDer.validator = cfg.Validator
+	}
+
+	if requestIDer.header == "" {
+		requestIDer.header = "X-Request-ID"
+	}
+
+	return func(ctx *amp.Ctx) error {
+		id := ctx.Request().Header.Get(requestIDer.header)
+		if !requestIDer.validator(id) {
+			id = requestIDer.generator()
+		}
+
+		ctx.SetRequestID(id)
+		ctx.Writer().Header().Set(requestIDer.header, id)
+		ctx.WithContext(context.WithValue(ctx.Request().Context(), contextKey{}, id))
+
+		return nil
+	}
+}
+
+// Get the request ID from a context, empty if it does not have one.
+// Works with the *amp.Ctx, and with the request context given to other libraries.
+func FromContext(c context.Context) string {
+	id, _ := c.Value(contextKey{}).(string)
+	return id
+}
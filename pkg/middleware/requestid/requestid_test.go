@@ -0,0 +1,98 @@
+package requestid
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joseph-beck/amp/pkg/amp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	a := amp.New()
+
+	a.Use(New())
+
+	var id string
+
+	a.Get("/test", func(ctx *amp.Ctx) error {
+		id = ctx.RequestID()
+		assert.Equal(t, id, FromContext(ctx))
+		assert.Equal(t, id, FromContext(ctx.Request().Context()))
+		return nil
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Len(t, id, 36)
+	assert.Equal(t, id, writer.Header().Get("X-Request-ID"))
+
+	request = httptest.NewRequest("GET", "/test", nil)
+	request.Header.Set("X-Request-ID", "given-id")
+	writer = httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Equal(t, "given-id", id)
+	assert.Equal(t, "given-id", writer.Header().Get("X-Request-ID"))
+
+	request = httptest.NewRequest("GET", "/test", nil)
+	request.Header.Set("X-Request-ID", "bad id")
+	writer = httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.NotEqual(t, "bad id", id)
+	assert.Equal(t, id, writer.Header().Get("X-Request-ID"))
+}
+
+func TestNewConfig(t *testing.T) {
+	a := amp.New()
+
+	a.Use(New(Config{
+		Header: "X-Correlation-ID",
+		Generator: func() string {
+			return "generated"
+		},
+		Validator: func(id string) bool {
+			return id == "valid"
+		},
+	}))
+
+	a.Get("/test", func(ctx *amp.Ctx) error {
+		return nil
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	request.Header.Set("X-Correlation-ID", "valid")
+	writer := httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Equal(t, "valid", writer.Header().Get("X-Correlation-ID"))
+
+	request = httptest.NewRequest("GET", "/test", nil)
+	request.Header.Set("X-Correlation-ID", "other")
+	writer = httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Equal(t, "generated", writer.Header().Get("X-Correlation-ID"))
+}
+
+func TestNewLogs(t *testing.T) {
+	logs := bytes.Buffer{}
+
+	logger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(logger)
+
+	a := amp.New()
+
+	a.Use(New())
+
+	a.Get("/test", func(ctx *amp.Ctx) error {
+		return nil
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	request.Header.Set("X-Request-ID", "logged-id")
+	writer := httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Contains(t, logs.String(), "request_id=logged-id")
+}
@@ -0,0 +1,39 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Timeout is a middleware used for bounding how long handlers can run for.
+package timeout
+
+import (
+	"time"
+
+	"github.com/joseph-beck/amp/pkg/amp"
+	"github.com/joseph-beck/amp/pkg/status"
+)
+
+// Configure the Amp Timeout middleware.
+type Config struct {
+	// How long the rest of the chain can run for, before the timeout response is given.
+	// When using the Default(), Timeout is 5 seconds.
+	Timeout time.Duration
+
+	// Status of the timeout response, for example status.GatewayTimeout.
+	// Not used if a TimeoutHandler is given.
+	// When using the Default(), Status is status.ServiceUnavailable.
+	Status int
+
+	// Handler that is called once the timeout has been reached.
+	// The Ctx is aborted before this is called, the returned error is handled by the Mux.
+	// When this is nil, an error with the Status is returned,
+	// which the Mux renders as problem details.
+	TimeoutHandler amp.Handler
+}
+
+// Returns the default configuration for the timeout middleware.
+func Default() Config {
+	return Config{
+		Timeout:        5 * time.Second,
+		Status:         status.ServiceUnavailable,
+		TimeoutHandler: nil,
+	}
+}
@@ -0,0 +1,16 @@
+package timeout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joseph-beck/amp/pkg/status"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefault(t *testing.T) {
+	cfg := Default()
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.Equal(t, status.ServiceUnavailable, cfg.Status)
+	assert.Nil(t, cfg.TimeoutHandler)
+}
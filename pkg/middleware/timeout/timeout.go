@@ -0,0 +1,185 @@
+// GitHub Repository: https://github.com/joseph-beck/amp
+// GoDocs: https://pkg.go.dev/github.com/joseph-beck/amp
+
+// Package Timeout is a middleware used for bounding how long handlers can run for.
+package timeout
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/joseph-beck/amp/pkg/amp"
+	amperr "github.com/joseph-beck/amp/pkg/error"
+)
+
+// unexported timeouter struct, used to store our timeout settings privately.
+type timeouter struct {
+	// unexported timeout.
+	timeout time.Duration
+
+	// unexported status.
+	status int
+
+	// unexported timeoutHandler.
+	// if this is nil, an error with the status is returned.
+	timeoutHandler amp.Handler
+}
+
+// Buffers the response of the rest of the chain,
+// so that nothing is written to the real writer until the chain has finished in time.
+// Once timed out, any writes fail with http.ErrHandlerTimeout.
+type timeoutWriter struct {
+	// Guards all of the members below,
+	// the chain writes from its own goroutine.
+	mu sync.Mutex
+
+	// Headers of the response, a copy of the headers of the Ctx.
+	header http.Header
+
+	// Buffered body of the response.
+	body bytes.Buffer
+
+	// Status of the response, 0 if it has not been set.
+	status int
+
+	// Has the body been written to?
+	wrote bool
+
+	// Has the timeout been reached?
+	timedOut bool
+}
+
+// Get the headers of the timeoutWriter.
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+// Set the status of the timeoutWriter, has no effect once the body has been written.
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut || w.wrote {
+		return
+	}
+
+	w.status = code
+}
+
+// Write to the buffered body, fails with http.ErrHandlerTimeout once timed out.
+func (w *timeoutWriter) Write(body []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	w.wrote = true
+	return w.body.Write(body)
+}
+
+// Create a new timeout middleware.
+// If this is given a config it will use that, otherwise Default() config is used.
+// The rest of the chain runs on a copy of the Ctx, in its own goroutine,
+// with a request context that is cancelled once the timeout is reached.
+// Values set on the Ctx by the rest of the chain are not seen by middleware before this.
+//
+//	a := amp.New()
+//
+//	a.Use(timeout.New(timeout.Config{
+//		Timeout: 2 * time.Second,
+//		Status:  status.GatewayTimeout,
+//	}))
+//
+// Handlers should stop once ctx.Done() is closed, anything they write after the timeout is discarded.
+func New(args ...Config) amp.Handler {
+	cfg := Default()
+
+	if len(args) > 0 {
+		cfg = args[0]
+	}
+
+	timeouter := timeouter{
+		timeout:        cfg.Timeout,
+		status:         cfg.Status,
+		timeoutHandler: cfg.TimeoutHandler,
+	}
+
+	return func(ctx *amp.Ctx) error {
+		c, cancel := context.WithTimeout(ctx.Request().Context(), timeouter.timeout)
+		defer cancel()
+
+		writer := &timeoutWriter{
+			header: ctx.Writer().Header().Clone(),
+		}
+
+		cp := ctx.Copy()
+		cp.SetWriter(writer)
+		cp.WithContext(c)
+
+		done := make(chan error, 1)
+		panics := make(chan any, 1)
+
+		go func() {
+			defer func() {
+				if v := recover(); v != nil {
+					panics <- v
+				}
+			}()
+
+			done <- cp.Next()
+		}()
+
+		select {
+		case v := <-panics:
+			// panic in the goroutine of the request, so that it can be recovered.
+			panic(v)
+
+		case err := <-done:
+			writer.mu.Lock()
+			defer writer.mu.Unlock()
+
+			// the chain has already ran on the copy.
+			if cp.Aborted() {
+				ctx.Abort()
+			} else {
+				ctx.Skip()
+			}
+
+			header := ctx.Writer().Header()
+			clear(header)
+			for key, val := range writer.header {
+				header[key] = val
+			}
+
+			if writer.status != 0 {
+				ctx.Status(writer.status)
+			}
+
+			if writer.wrote {
+				if _, werr := ctx.WriteBytes(writer.body.Bytes()); werr != nil {
+					return werr
+				}
+			}
+
+			return err
+
+		case <-c.Done():
+			writer.mu.Lock()
+			writer.timedOut = true
+			writer.mu.Unlock()
+
+			ctx.Abort()
+
+			if timeouter.timeoutHandler != nil {
+				return timeouter.timeoutHandler(ctx)
+			}
+
+			return amperr.New(timeouter.status, "").WithCause(c.Err())
+		}
+	}
+}
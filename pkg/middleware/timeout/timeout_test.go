@@ -0,0 +1,121 @@
+package timeout
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joseph-beck/amp/pkg/amp"
+	"github.com/joseph-beck/amp/pkg/status"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	a := amp.New()
+
+	a.Use(New(Config{
+		Timeout: 50 * time.Millisecond,
+		Status:  status.ServiceUnavailable,
+	}))
+
+	a.Get("/test/one", func(ctx *amp.Ctx) error {
+		ctx.Header("X-Handler", "true")
+		return ctx.Render(status.Created, "fast")
+	})
+
+	request := httptest.NewRequest("GET", "/test/one", nil)
+	writer := httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Equal(t, status.Created, writer.Code)
+	assert.Equal(t, "fast", writer.Body.String())
+	assert.Equal(t, "true", writer.Header().Get("X-Handler"))
+
+	late := make(chan error, 1)
+
+	a.Get("/test/two", func(ctx *amp.Ctx) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+
+		_, err := ctx.Write("late")
+		late <- err
+		return err
+	})
+
+	request = httptest.NewRequest("GET", "/test/two", nil)
+	writer = httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Equal(t, status.ServiceUnavailable, writer.Code)
+	assert.NotContains(t, writer.Body.String(), "late")
+
+	select {
+	case err := <-late:
+		assert.ErrorIs(t, err, http.ErrHandlerTimeout)
+	case <-time.After(1 * time.Second):
+		t.Fatal("handler did not see the timeout")
+	}
+}
+
+func TestNewTimeoutHandler(t *testing.T) {
+	a := amp.New()
+
+	a.Use(New(Config{
+		Timeout: 10 * time.Millisecond,
+		TimeoutHandler: func(ctx *amp.Ctx) error {
+			return ctx.Render(status.GatewayTimeout, "too slow")
+		},
+	}))
+
+	a.Get("/test", func(ctx *amp.Ctx) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	request := httptest.NewRequest("GET", "/test", nil)
+	writer := httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Equal(t, status.GatewayTimeout, writer.Code)
+	assert.Equal(t, "too slow", writer.Body.String())
+}
+
+func TestNewChain(t *testing.T) {
+	a := amp.New()
+
+	ran := 0
+
+	a.Use(New())
+
+	a.Get("/test/one", func(ctx *amp.Ctx) error {
+		ran++
+		return nil
+	})
+
+	request := httptest.NewRequest("GET", "/test/one", nil)
+	writer := httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Equal(t, 1, ran)
+	assert.Equal(t, status.OK, writer.Code)
+
+	a.Get("/test/two", func(ctx *amp.Ctx) error {
+		return errors.New("boom")
+	})
+
+	request = httptest.NewRequest("GET", "/test/two", nil)
+	writer = httptest.NewRecorder()
+	a.ServeHTTP(writer, request)
+	assert.Equal(t, status.InternalServerError, writer.Code)
+
+	a.Get("/test/three", func(ctx *amp.Ctx) error {
+		panic("boom")
+	})
+
+	request = httptest.NewRequest("GET", "/test/three", nil)
+	writer = httptest.NewRecorder()
+	assert.PanicsWithValue(t, "boom", func() {
+		a.ServeHTTP(writer, request)
+	})
+}